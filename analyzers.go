@@ -0,0 +1,235 @@
+package holadoc
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Analyzer turns text into the terms of the search index. The search
+// component of the default theme analyzes queries with the analyzer of the
+// same name written in JS, unknown names are analyzed as "standard".
+type Analyzer interface {
+	Name() string
+	Analyze(text string) []string
+}
+
+// analyzers indexed by language, languages without analyzer use the standard
+// one
+var analyzers = map[string]Analyzer{
+	"en": englishAnalyzer{},
+	"es": spanishAnalyzer{},
+	"zh": cjkAnalyzer{},
+	"ja": cjkAnalyzer{},
+	"ko": cjkAnalyzer{},
+}
+
+// RegisterAnalyzer sets the analyzer of a language
+func RegisterAnalyzer(lang string, analyzer Analyzer) {
+	analyzers[lang] = analyzer
+}
+
+func getAnalyzer(lang string) Analyzer {
+	if analyzer, ok := analyzers[lang]; ok {
+		return analyzer
+	}
+	return standardAnalyzer{}
+}
+
+// standardAnalyzer splits words, lowercases them and removes accents
+type standardAnalyzer struct{}
+
+func (standardAnalyzer) Name() string {
+	return "standard"
+}
+
+func (standardAnalyzer) Analyze(text string) []string {
+	return splitWords(foldAccents(strings.ToLower(text)))
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r)
+	})
+}
+
+// foldAccents removes diacritics: índice is indice
+func foldAccents(text string) string {
+	decomposed := norm.NFD.String(text)
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, decomposed)
+}
+
+// analyze applies the standard analyzer, removes stop words and stems
+func analyze(text string, stopWords map[string]bool, stem func(string) string) []string {
+	result := []string{}
+	for _, word := range (standardAnalyzer{}).Analyze(text) {
+		if stopWords[word] {
+			continue
+		}
+		result = append(result, stem(word))
+	}
+	return result
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+type englishAnalyzer struct{}
+
+var englishStopWords = wordSet(`a an and are as at be but by for from has have
+how i if in into is it its of on or that the their then there these they this
+to was were what when where which will with you your`)
+
+func (englishAnalyzer) Name() string {
+	return "en"
+}
+
+func (englishAnalyzer) Analyze(text string) []string {
+	return analyze(text, englishStopWords, stemEnglish)
+}
+
+// stemEnglish is a light stemmer: plurals, -ing, -ed, -ly and final e. The
+// search component implements the same rules in JS.
+func stemEnglish(w string) string {
+
+	if len(w) <= 3 {
+		return w
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case hasAnySuffix(w, "xes", "ches", "shes", "zes"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !hasAnySuffix(w, "ss", "us", "is"):
+		w = w[:len(w)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed"} {
+		stem, found := strings.CutSuffix(w, suffix)
+		if !found || len(stem) < 3 || !strings.ContainsAny(stem, "aeiouy") {
+			continue
+		}
+		w = stem
+		// running is run
+		if n := len(w); w[n-1] == w[n-2] && w[n-1] < unicode.MaxASCII && !strings.ContainsRune("aeiouylsz", rune(w[n-1])) {
+			w = w[:n-1]
+		}
+		break
+	}
+
+	if stem, found := strings.CutSuffix(w, "ly"); found && len(stem) >= 3 {
+		w = stem
+	}
+
+	if len(w) > 4 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+
+	return w
+}
+
+type spanishAnalyzer struct{}
+
+var spanishStopWords = wordSet(`a al como con de del desde donde el en entre es
+esta este eso hay la las le lo los mas me muy no nos o para pero por que se sin
+sobre son su sus tambien un una uno y ya`)
+
+func (spanishAnalyzer) Name() string {
+	return "es"
+}
+
+func (spanishAnalyzer) Analyze(text string) []string {
+	return analyze(text, spanishStopWords, stemSpanish)
+}
+
+// stemSpanish is a light stemmer: plurals and gender. Accents are already
+// folded. The search component implements the same rules in JS.
+func stemSpanish(w string) string {
+
+	if len(w) >= 5 {
+		switch {
+		case hasAnySuffix(w, "os", "as", "es"):
+			w = w[:len(w)-2]
+		case strings.HasSuffix(w, "s"):
+			w = w[:len(w)-1]
+		}
+	}
+
+	if len(w) >= 4 && hasAnySuffix(w, "o", "a", "e") {
+		w = w[:len(w)-1]
+	}
+
+	return w
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cjkAnalyzer splits Chinese, Japanese and Korean text, written without
+// spaces, in overlapping bigrams: 索引类型 is 索引 引类 类型. Other words are
+// analyzed by the standard analyzer.
+type cjkAnalyzer struct{}
+
+func (cjkAnalyzer) Name() string {
+	return "cjk"
+}
+
+func (cjkAnalyzer) Analyze(text string) []string {
+
+	result := []string{}
+	other := &strings.Builder{}
+	run := []rune{}
+
+	flush := func() {
+		result = append(result, (standardAnalyzer{}).Analyze(other.String())...)
+		other.Reset()
+		if len(run) == 1 {
+			result = append(result, string(run))
+		}
+		for i := 0; i+1 < len(run); i++ {
+			result = append(result, string(run[i:i+2]))
+		}
+		run = run[:0]
+	}
+
+	for _, r := range text {
+		if isCJK(r) {
+			if other.Len() > 0 {
+				flush()
+			}
+			run = append(run, r)
+			continue
+		}
+		if len(run) > 0 {
+			flush()
+		}
+		other.WriteRune(r)
+	}
+	flush()
+
+	return result
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,105 @@
+package holadoc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStemEnglish(t *testing.T) {
+
+	cases := []struct {
+		word string
+		want string
+	}{
+		{"run", "run"},
+		{"bus", "bus"},
+		{"status", "status"},
+		{"class", "class"},
+		{"collections", "collection"},
+		{"indexes", "index"},
+		{"queries", "query"},
+		{"classes", "class"},
+		{"running", "run"},
+		{"indexing", "index"},
+		{"create", "creat"},
+		{"created", "creat"},
+		{"creates", "creat"},
+		{"quickly", "quick"},
+		{"bring", "bring"},
+	}
+
+	for _, c := range cases {
+		if got := stemEnglish(c.word); got != c.want {
+			t.Errorf("stemEnglish(%q) = %q, want %q", c.word, got, c.want)
+		}
+	}
+}
+
+func TestStemSpanish(t *testing.T) {
+
+	cases := []struct {
+		word string
+		want string
+	}{
+		{"en", "en"},
+		{"colecciones", "coleccion"},
+		{"coleccion", "coleccion"},
+		{"indices", "indic"},
+		{"indice", "indic"},
+		{"nuevos", "nuev"},
+		{"nueva", "nuev"},
+		{"nuevo", "nuev"},
+		{"datos", "dat"},
+	}
+
+	for _, c := range cases {
+		if got := stemSpanish(c.word); got != c.want {
+			t.Errorf("stemSpanish(%q) = %q, want %q", c.word, got, c.want)
+		}
+	}
+}
+
+func TestAnalyzers(t *testing.T) {
+
+	cases := []struct {
+		lang string
+		text string
+		want []string
+	}{
+		{"en", "The running indexes", []string{"run", "index"}},
+		{"en", "", []string{}},
+		{"es", "Los índices de las colecciones", []string{"indic", "coleccion"}},
+		{"fr", "Crème Brûlée", []string{"creme", "brulee"}},
+		{"zh", "索引类型", []string{"索引", "引类", "类型"}},
+		{"zh", "搜", []string{"搜"}},
+		{"zh", "使用Go语言", []string{"使用", "go", "语言"}},
+		{"zh", "创建 collections", []string{"创建", "collections"}},
+		{"ja", "検索エンジン", []string{"検索", "索エ", "エン", "ンジ", "ジン"}},
+	}
+
+	for _, c := range cases {
+		got := getAnalyzer(c.lang).Analyze(c.text)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("%s: Analyze(%q) = %q, want %q", c.lang, c.text, got, c.want)
+		}
+	}
+}
+
+func TestFoldAccents(t *testing.T) {
+
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"índice", "indice"},
+		{"Pérez", "Perez"},
+		{"año", "ano"},
+		{"索引", "索引"},
+	}
+
+	for _, c := range cases {
+		if got := foldAccents(c.text); got != c.want {
+			t.Errorf("foldAccents(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package holadoc
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blogLayout is the layout of the listing pages of a blog: the blog section
+// itself, the next pages, archives and authors. Src can override it with its
+// own `layouts/posts.gohtml`.
+const blogLayout = "posts"
+
+// blogPaginate is the default number of posts of a listing page
+const blogPaginate = 10
+
+// moreMarker cuts the summary of a post: <!--more-->
+const moreMarker = "more"
+
+// BlogOptions configures a blog section in site.json
+type BlogOptions struct {
+	Paginate int `json:"paginate"` // posts per listing page
+}
+
+// Blogs knows the sections that are blogs, marked in front matter with
+// `type: blog` or in site.json with `blogs`. Their posts are the pages under
+// them sorted by date, newest first.
+type Blogs struct {
+	sections map[string]*BlogOptions // node path: options
+	written  map[string]bool         // generated pages, by output path
+}
+
+// Post is a page of a blog with its date and summary
+type Post struct {
+	*Page
+	Date    time.Time
+	Summary template.HTML // content before <!--more-->, or the description
+	More    bool          // the summary is cut, the post has more content
+	Authors []*Author
+}
+
+// Paginator is a listing page of a blog
+type Paginator struct {
+	Posts  []*Post
+	Number int // from 1
+	Total  int
+	Prev   string // url, empty in the first page
+	Next   string // url, empty in the last page
+}
+
+// Archive is a year or a month of a blog with the number of posts
+type Archive struct {
+	Title  string
+	Url    string
+	Year   int
+	Month  time.Month // 0 for years
+	Count  int
+	Months []*Archive // only for years
+}
+
+// Author is an author of a blog with the number of posts
+type Author struct {
+	Name  string
+	Url   string
+	Count int
+}
+
+func newBlogs(site *Site) *Blogs {
+	sections := map[string]*BlogOptions{}
+	for nodePath, options := range site.Blogs {
+		if options == nil {
+			options = &BlogOptions{}
+		}
+		sections[strings.Trim(nodePath, "/")] = options
+	}
+	return &Blogs{
+		sections: sections,
+		written:  map[string]bool{},
+	}
+}
+
+// getPaginate returns the posts per page of a blog section or 0 if the node
+// is not a blog. Values that are not positive get the default.
+func (b *Blogs) getPaginate(n *Node, lang, version string) int {
+
+	paginate := 0
+	if options, ok := b.sections[getNodePath(n)]; ok {
+		paginate = cmp.Or(max(options.Paginate, 0), blogPaginate)
+	}
+
+	if variation := getBestVariation(n.Variations, lang, version); variation != nil && variation.FrontMatter.Type == "blog" {
+		p, _ := asInt(variation.FrontMatter.Params["paginate"])
+		paginate = cmp.Or(max(p, 0), paginate, blogPaginate)
+	}
+
+	return paginate
+}
+
+// isPost returns true if the node is under a blog section
+func (b *Blogs) isPost(n *Node, lang, version string) bool {
+	for parent := getParent(n); parent != nil; parent = getParent(parent) {
+		if b.getPaginate(parent, lang, version) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getDate returns the date of a page: front matter `date` or `publishDate`,
+// the date of the node name or the date of the last commit
+func getDate(n *Node, variation *Variation) time.Time {
+	if !variation.FrontMatter.PublishDate.IsZero() {
+		return variation.FrontMatter.PublishDate
+	}
+	if !n.Date.IsZero() {
+		return n.Date
+	}
+	if variation.Filename != "" {
+		return getLastmod(variation.Filename)
+	}
+	return time.Time{}
+}
+
+// getPosts returns the pages under a blog section written in lang, newest
+// first. Hidden pages, links and separators are left out.
+func getPosts(blog *Node, lang, version string) []*Post {
+
+	posts := []*Post{}
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, child := range getChildren(n) {
+			if isHidden(child, lang, version) || !isPage(child, lang, version) {
+				continue
+			}
+			variation := getBestVariation(child.Variations, lang, version)
+			if variation != nil && variation.Filename != "" && hasContent(child, lang, version) {
+				posts = append(posts, newPost(blog, child, variation, lang, version))
+			}
+			walk(child)
+		}
+	}
+	walk(blog)
+
+	slices.SortStableFunc(posts, func(a, b *Post) int {
+		return b.Date.Compare(a.Date)
+	})
+
+	return posts
+}
+
+func newPost(blog, n *Node, variation *Variation, lang, version string) *Post {
+
+	post := &Post{
+		Page: newPage(n, nil, lang, version),
+		Date: getDate(n, variation),
+	}
+
+	post.Summary, post.More = getSummary(variation, post.Url)
+
+	for _, name := range variation.FrontMatter.Authors {
+		post.Authors = append(post.Authors, &Author{
+			Name: name,
+			Url:  getSubpageLink(blog, lang, version, "authors", slugify(name)),
+		})
+	}
+
+	return post
+}
+
+// getSummary returns the content of a post before <!--more--> with absolute
+// links, or the description if it has no marker
+func getSummary(variation *Variation, link string) (template.HTML, bool) {
+
+	_, htmlReader := readSource(variation.Filename)
+	nodes, err := html.ParseFragment(htmlReader, &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	b := &bytes.Buffer{}
+	for _, n := range nodes {
+		if n.Type == html.CommentNode && strings.TrimSpace(n.Data) == moreMarker {
+			return template.HTML(getAbsoluteHtml(b.String(), link)), true
+		}
+		html.Render(b, n)
+	}
+
+	if variation.Description == "" {
+		return "", false
+	}
+	return template.HTML("<p>" + html.EscapeString(variation.Description) + "</p>"), false
+}
+
+// paginate splits posts in listing pages, the first one is the blog section
+// itself and the next ones are `page/2`, `page/3`...
+func paginate(blog *Node, posts []*Post, size int, lang, version string, parts ...string) []*Paginator {
+
+	if size <= 0 {
+		size = blogPaginate
+	}
+
+	pages := []*Paginator{}
+	total := max(1, (len(posts)+size-1)/size)
+
+	link := func(number int) string {
+		if number == 1 {
+			if len(parts) == 0 {
+				return getLink(blog, lang, version)
+			}
+			return getSubpageLink(blog, lang, version, parts...)
+		}
+		return getSubpageLink(blog, lang, version, slices.Concat(parts, []string{"page", strconv.Itoa(number)})...)
+	}
+
+	for number := 1; number <= total; number++ {
+		p := &Paginator{
+			Posts:  posts[min((number-1)*size, len(posts)):min(number*size, len(posts))],
+			Number: number,
+			Total:  total,
+		}
+		if number > 1 {
+			p.Prev = link(number - 1)
+		}
+		if number < total {
+			p.Next = link(number + 1)
+		}
+		pages = append(pages, p)
+	}
+
+	return pages
+}
+
+// getArchives returns the years of the posts, newest first, with their months
+func getArchives(blog *Node, posts []*Post, lang, version string) []*Archive {
+
+	years := []*Archive{}
+
+	for _, post := range posts {
+		if post.Date.IsZero() {
+			continue
+		}
+		year, month := post.Date.Year(), post.Date.Month()
+
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, &Archive{
+				Title: strconv.Itoa(year),
+				Url:   getSubpageLink(blog, lang, version, strconv.Itoa(year)),
+				Year:  year,
+			})
+		}
+		y := years[len(years)-1]
+		y.Count++
+
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, &Archive{
+				Title: getMonthName(lang, month) + " " + strconv.Itoa(year),
+				Url:   getSubpageLink(blog, lang, version, strconv.Itoa(year), fmt.Sprintf("%02d", month)),
+				Year:  year,
+				Month: month,
+			})
+		}
+		y.Months[len(y.Months)-1].Count++
+	}
+
+	return years
+}
+
+// getMonthName returns the name of a month from the catalog of lang:
+// `month.5`, English names are the default
+func getMonthName(lang string, month time.Month) string {
+	if name, ok := catalogs[lang]["month."+strconv.Itoa(int(month))]; ok {
+		return name
+	}
+	return month.String()
+}
+
+// getAuthors returns the authors of the posts sorted by name. Names with the
+// same slug share the author page, they are merged under the name of the
+// newest post.
+func getAuthors(blog *Node, posts []*Post, lang, version string) []*Author {
+
+	authors := map[string]*Author{} // by url
+	for _, post := range posts {
+		counted := map[string]bool{}
+		for _, author := range post.Authors {
+			a := authors[author.Url]
+			if a == nil {
+				a = &Author{Name: author.Name, Url: author.Url}
+				authors[author.Url] = a
+			} else if a.Name != author.Name {
+				warnOnce(fmt.Sprintf("WARNING: blog authors '%s' and '%s' share the page %s, merged as '%s'", a.Name, author.Name, author.Url, a.Name))
+			}
+			if !counted[author.Url] {
+				counted[author.Url] = true
+				a.Count++
+			}
+		}
+	}
+
+	result := []*Author{}
+	for _, author := range authors {
+		result = append(result, author)
+	}
+	slices.SortFunc(result, func(a, b *Author) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return result
+}
+
+// listingVariation returns the variation of a blog section rendered with the
+// blog layout, unless its front matter chooses another one
+func listingVariation(variation *Variation, title string) *Variation {
+
+	v := *variation
+	frontMatter := *variation.FrontMatter
+	v.FrontMatter = &frontMatter
+	v.Title = title
+
+	if frontMatter.Layout == "" || frontMatter.Layout == sectionLayout {
+		frontMatter.Layout = blogLayout
+	}
+
+	return &v
+}
+
+// data adds the first listing page, archives and authors to the data of a
+// blog section page
+func (b *Blogs) data(blog *Node, lang, version string, data map[string]any) {
+
+	posts := getPosts(blog, lang, version)
+	size := b.getPaginate(blog, lang, version)
+
+	data["paginator"] = paginate(blog, posts, size, lang, version)[0]
+	data["archives"] = getArchives(blog, posts, lang, version)
+	data["authors"] = getAuthors(blog, posts, lang, version)
+}
+
+// write writes the pages generated for every blog section: next listing
+// pages, year and month archives and author pages
+func (b *Blogs) write(root *Node, writer *pageWriter) {
+
+	traverseNodes(root, func(blog *Node) {
+		for _, version := range versions {
+			for _, lang := range languages {
+
+				size := b.getPaginate(blog, lang, version)
+				if size == 0 {
+					continue
+				}
+
+				variation := getBestVariation(blog.Variations, lang, version)
+				if variation == nil || isAutoSection(blog, lang, version) {
+					variation = newSectionVariation(blog, lang, version)
+				}
+
+				posts := getPosts(blog, lang, version)
+				archives := getArchives(blog, posts, lang, version)
+				authors := getAuthors(blog, posts, lang, version)
+
+				write := func(title string, paginator *Paginator, parts ...string) {
+
+					outputPath := getSubpageOutputPath(blog, variation, lang, version, parts...)
+					if b.written[outputPath] {
+						return
+					}
+					b.written[outputPath] = true
+
+					v := listingVariation(variation, title)
+					v.Filename = ""
+					v.Description = ""
+					v.FrontMatter.Template = ""
+
+					data := writer.data(blog, v, lang, version)
+					data["alternates"] = []Alternate{} // generated pages are not translated
+					data["paginator"] = paginator
+					data["archives"] = archives
+					data["authors"] = authors
+					data["index"] = template.HTML("")
+					data["content"] = template.HTML("")
+
+					writer.write(blog, v, lang, version, outputPath, data)
+				}
+
+				// listing, the first page is the blog section itself
+				for _, p := range paginate(blog, posts, size, lang, version)[1:] {
+					write(fmt.Sprintf("%s (%d/%d)", variation.Title, p.Number, p.Total), p, "page", strconv.Itoa(p.Number))
+				}
+
+				// archives
+				for _, year := range archives {
+					yearPosts := filterPosts(posts, func(p *Post) bool {
+						return p.Date.Year() == year.Year
+					})
+					writePaginated(write, blog, yearPosts, size, lang, version, year.Title, strconv.Itoa(year.Year))
+
+					for _, month := range year.Months {
+						monthPosts := filterPosts(yearPosts, func(p *Post) bool {
+							return p.Date.Month() == month.Month
+						})
+						writePaginated(write, blog, monthPosts, size, lang, version, month.Title, strconv.Itoa(year.Year), fmt.Sprintf("%02d", month.Month))
+					}
+				}
+
+				// authors, the index has no paginator
+				if len(authors) > 0 {
+					write(translate(lang, "blog.authors"), nil, "authors")
+				}
+				for _, author := range authors {
+					authorPosts := filterPosts(posts, func(p *Post) bool {
+						return slices.ContainsFunc(p.Authors, func(a *Author) bool { return a.Url == author.Url })
+					})
+					writePaginated(write, blog, authorPosts, size, lang, version, author.Name, "authors", slugify(author.Name))
+				}
+			}
+		}
+	})
+}
+
+func writePaginated(write func(title string, paginator *Paginator, parts ...string), blog *Node, posts []*Post, size int, lang, version, title string, parts ...string) {
+	for _, p := range paginate(blog, posts, size, lang, version, parts...) {
+		if p.Number == 1 {
+			write(title, p, parts...)
+			continue
+		}
+		write(fmt.Sprintf("%s (%d/%d)", title, p.Number, p.Total), p, slices.Concat(parts, []string{"page", strconv.Itoa(p.Number)})...)
+	}
+}
+
+func filterPosts(posts []*Post, keep func(p *Post) bool) []*Post {
+	result := []*Post{}
+	for _, post := range posts {
+		if keep(post) {
+			result = append(result, post)
+		}
+	}
+	return result
+}
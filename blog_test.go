@@ -0,0 +1,243 @@
+package holadoc
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestGetSummary(t *testing.T) {
+
+	defer func(u string) { baseurl = u }(baseurl)
+	baseurl = "https://hola.cloud"
+
+	cases := []struct {
+		name        string
+		src         string
+		description string
+		want        string
+		more        bool
+	}{
+		{"more", "# Title\n\nFirst [link](other.html)\n\n<!--more-->\n\nRest\n", "", "\n<p>First <a href=\"https://hola.cloud/blog/post/other.html\">link</a></p>\n", true},
+		{"more with spaces", "Intro\n\n<!-- more -->\n\nRest\n", "", "<p>Intro</p>\n", true},
+		{"description", "# Title\n\nWhole post\n", "A <b>post</b>", "<p>A &lt;b&gt;post&lt;/b&gt;</p>", false},
+		{"nothing", "# Title\n\nWhole post\n", "", "", false},
+		{"other comment", "Intro\n\n<!-- note -->\n\nRest\n", "", "", false},
+	}
+
+	dir := t.TempDir()
+
+	for _, c := range cases {
+		filename := path.Join(dir, "post_en.md")
+		err := os.WriteFile(filename, []byte(c.src), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+		variation := &Variation{Filename: filename, Description: c.description}
+		got, more := getSummary(variation, "/blog/post/index.html")
+		if string(got) != c.want || more != c.more {
+			t.Errorf("%s: getSummary = %q, %v, want %q, %v", c.name, got, more, c.want, c.more)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+
+	defer func(l []string) { languages = l }(languages)
+	languages = []string{"en"}
+
+	blog := &Node{
+		Name:   "blog",
+		Parent: &Node{},
+		Variations: []*Variation{
+			{Url: "blog", Language: "en", FrontMatter: &FrontMatter{}},
+		},
+	}
+
+	cases := []struct {
+		posts int
+		size  int
+		want  []int // posts of every page
+	}{
+		{0, 10, []int{0}},
+		{1, 10, []int{1}},
+		{4, 2, []int{2, 2}},
+		{5, 2, []int{2, 2, 1}},
+		{3, 1, []int{1, 1, 1}},
+		{3, 0, []int{3}},       // default size
+		{12, -1, []int{10, 2}}, // default size
+	}
+
+	for _, c := range cases {
+
+		posts := make([]*Post, c.posts)
+		pages := paginate(blog, posts, c.size, "en", "")
+
+		if len(pages) != len(c.want) {
+			t.Errorf("paginate(%d posts, %d) has %d pages, want %d", c.posts, c.size, len(pages), len(c.want))
+			continue
+		}
+
+		for i, p := range pages {
+			if len(p.Posts) != c.want[i] || p.Number != i+1 || p.Total != len(c.want) {
+				t.Errorf("paginate(%d posts, %d) page %d has %d posts (%d of %d), want %d", c.posts, c.size, i+1, len(p.Posts), p.Number, p.Total, c.want[i])
+			}
+		}
+
+		first, last := pages[0], pages[len(pages)-1]
+		if first.Prev != "" || last.Next != "" {
+			t.Errorf("paginate(%d posts, %d) links before the first or after the last page", c.posts, c.size)
+		}
+		if len(pages) > 1 && (first.Next != "/blog/page/2/index.html" || pages[1].Prev != "/blog/index.html") {
+			t.Errorf("paginate(%d posts, %d) links are %q and %q", c.posts, c.size, first.Next, pages[1].Prev)
+		}
+	}
+}
+
+func TestGetPaginate(t *testing.T) {
+
+	blogs := newBlogs(&Site{Blogs: map[string]*BlogOptions{
+		"news":     {Paginate: 3},
+		"/events/": {Paginate: -1},
+		"empty":    nil,
+	}})
+
+	newNode := func(name string, params map[string]any) *Node {
+		frontMatter := &FrontMatter{Params: params}
+		if params != nil {
+			frontMatter.Type = "blog"
+		}
+		return &Node{
+			Name:       name,
+			Parent:     &Node{},
+			Variations: []*Variation{{Language: "en", FrontMatter: frontMatter}},
+		}
+	}
+
+	cases := []struct {
+		node *Node
+		want int
+	}{
+		{newNode("docs", nil), 0},
+		{newNode("news", nil), 3},
+		{newNode("events", nil), blogPaginate},
+		{newNode("empty", nil), blogPaginate},
+		{newNode("posts", map[string]any{}), blogPaginate},
+		{newNode("posts", map[string]any{"paginate": 4}), 4},
+		{newNode("posts", map[string]any{"paginate": -5}), blogPaginate},
+		{newNode("news", map[string]any{"paginate": 0}), 3},
+		{newNode("news", map[string]any{"paginate": -2}), 3},
+	}
+
+	for _, c := range cases {
+		if got := blogs.getPaginate(c.node, "en", ""); got != c.want {
+			t.Errorf("getPaginate(%s, %v) = %d, want %d", c.node.Name, c.node.Variations[0].FrontMatter.Params, got, c.want)
+		}
+	}
+}
+
+func TestGetDate(t *testing.T) {
+
+	published := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	named := time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		node      *Node
+		variation *Variation
+		want      time.Time
+	}{
+		{"front matter", &Node{Date: named}, &Variation{FrontMatter: &FrontMatter{PublishDate: published}}, published},
+		{"node name", &Node{Date: named}, &Variation{FrontMatter: &FrontMatter{}}, named},
+		{"unknown", &Node{}, &Variation{FrontMatter: &FrontMatter{}}, time.Time{}},
+	}
+
+	for _, c := range cases {
+		if got := getDate(c.node, c.variation); !got.Equal(c.want) {
+			t.Errorf("%s: getDate = %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestIsPost(t *testing.T) {
+
+	blogs := newBlogs(&Site{Blogs: map[string]*BlogOptions{"news": nil}})
+
+	root := &Node{}
+	newNode := func(name string, parent *Node, frontMatter *FrontMatter) *Node {
+		n := &Node{Name: name, Parent: parent, Variations: []*Variation{
+			{Language: "en", FrontMatter: frontMatter},
+		}}
+		parent.Children = append(parent.Children, n)
+		return n
+	}
+
+	news := newNode("news", root, &FrontMatter{})
+	release := newNode("release", news, &FrontMatter{})
+	changelog := newNode("changelog", release, &FrontMatter{})
+	posts := newNode("posts", root, &FrontMatter{Type: "blog"})
+	hello := newNode("hello", posts, &FrontMatter{})
+	docs := newNode("docs", root, &FrontMatter{})
+	intro := newNode("intro", docs, &FrontMatter{Layout: "blog"})
+
+	cases := []struct {
+		node *Node
+		want bool
+	}{
+		{news, false},
+		{release, true},
+		{changelog, true},
+		{posts, false},
+		{hello, true},
+		{docs, false},
+		{intro, false},
+	}
+
+	for _, c := range cases {
+		if got := blogs.isPost(c.node, "en", ""); got != c.want {
+			t.Errorf("isPost(%s) = %v, want %v", c.node.Name, got, c.want)
+		}
+	}
+}
+
+func TestGetAuthors(t *testing.T) {
+
+	defer func(d map[string]bool) { diagnostics = d }(diagnostics)
+	diagnostics = map[string]bool{}
+
+	author := func(name string) *Author {
+		return &Author{Name: name, Url: "/blog/authors/" + slugify(name) + "/index.html"}
+	}
+	posts := []*Post{
+		{Authors: []*Author{author("Ana Pérez")}},
+		{Authors: []*Author{author("Ana Perez"), author("fulldump")}},
+		{Authors: []*Author{author("ana perez"), author("Ana Pérez")}}, // counted once
+		{Authors: []*Author{author("Bob")}},
+	}
+
+	cases := []struct {
+		name  string
+		url   string
+		count int
+	}{
+		{"Ana Pérez", "/blog/authors/ana-perez/index.html", 3},
+		{"Bob", "/blog/authors/bob/index.html", 1},
+		{"fulldump", "/blog/authors/fulldump/index.html", 1},
+	}
+
+	authors := getAuthors(nil, posts, "en", "")
+	if len(authors) != len(cases) {
+		t.Fatalf("getAuthors has %d authors, want %d", len(authors), len(cases))
+	}
+	for i, c := range cases {
+		a := authors[i]
+		if a.Name != c.name || a.Url != c.url || a.Count != c.count {
+			t.Errorf("author %d = %s %s %d, want %s %s %d", i, a.Name, a.Url, a.Count, c.name, c.url, c.count)
+		}
+	}
+
+	if len(diagnostics) != 2 {
+		t.Errorf("getAuthors printed %d warnings, want one per merged name", len(diagnostics))
+	}
+}
@@ -26,11 +26,17 @@ func main() {
 		os.Exit(0)
 	}
 
+	holadoc.HolaDoc(c)
+
 	if c.Serve != "" {
 
+		mux := http.NewServeMux()
+		mux.HandleFunc("/_search", holadoc.SearchHandler)
+		mux.Handle("/", http.FileServer(http.Dir(c.Www)))
+
 		s := &http.Server{
 			Addr:    c.Serve,
-			Handler: http.FileServer(http.Dir(c.Www)),
+			Handler: mux,
 		}
 
 		s.ListenAndServe()
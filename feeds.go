@@ -0,0 +1,474 @@
+package holadoc
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// content of the entries of a feed
+const (
+	feedFull    = "full"    // the whole page
+	feedSummary = "summary" // only the description
+)
+
+// files written in the directory of every feed section
+const (
+	atomFile     = "atom.xml"
+	rssFile      = "rss.xml"
+	jsonFeedFile = "feed.json"
+)
+
+// Feeds collects the pages of the sections marked as feeds, in front matter
+// with `feed: true`, `feed: summary`... or in site.json with `feeds`
+type Feeds struct {
+	sections map[string]string // node path: content
+	feeds    map[string]*feed  // indexed by section link
+}
+
+type feed struct {
+	title       string
+	description string
+	language    string
+	url         string
+	dir         string // relative to www
+	content     string
+	entries     []*feedEntry
+	written     map[string]bool
+}
+
+type feedEntry struct {
+	title     string
+	url       string
+	summary   string
+	content   string // html with absolute links, empty for summary feeds
+	image     string
+	authors   []string
+	tags      []string
+	published time.Time
+	updated   time.Time
+}
+
+// FeedLink is a feed of the current page or its ancestors, for
+// <link rel="alternate">
+type FeedLink struct {
+	Type  string
+	Title string
+	Url   string
+}
+
+func newFeeds(site *Site) *Feeds {
+	sections := map[string]string{}
+	for nodePath, content := range site.Feeds {
+		sections[strings.Trim(nodePath, "/")] = asFeed(content)
+	}
+	return &Feeds{
+		sections: sections,
+		feeds:    map[string]*feed{},
+	}
+}
+
+// asFeed accepts true (full content), "full" or "summary"
+func asFeed(v any) string {
+	if s, ok := v.(string); ok && in([]string{feedFull, feedSummary}, strings.ToLower(s)) {
+		return strings.ToLower(s)
+	}
+	if asBool(v) {
+		return feedFull
+	}
+	return ""
+}
+
+// getContent returns the content of the feed of a section or "" if it is
+// not a feed
+func (f *Feeds) getContent(n *Node, lang, version string) string {
+	if variation := getBestVariation(n.Variations, lang, version); variation != nil && variation.FrontMatter.Feed != "" {
+		return variation.FrontMatter.Feed
+	}
+	return f.sections[getNodePath(n)]
+}
+
+func (f *Feeds) getFeed(section *Node, lang, version string) *feed {
+
+	link := getLink(section, lang, version)
+	if ff, exists := f.feeds[link]; exists {
+		return ff
+	}
+
+	variation := getBestVariation(section.Variations, lang, version)
+	if variation == nil || isAutoSection(section, lang, version) {
+		variation = newSectionVariation(section, lang, version)
+	}
+
+	ff := &feed{
+		title:       variation.Title,
+		description: variation.Description,
+		language:    lang,
+		url:         getAbsoluteLink(section, lang, version),
+		dir:         path.Dir(getOutputPath(section, variation, lang, version)),
+		content:     f.getContent(section, lang, version),
+		written:     map[string]bool{},
+	}
+	f.feeds[link] = ff
+
+	return ff
+}
+
+// add registers a written page in the feeds of the section itself and of its
+// ancestors. Only pages written in lang are entries, not fallbacks.
+func (f *Feeds) add(node *Node, variation *Variation, lang, version, content, image string) {
+
+	if f.getContent(node, lang, version) != "" {
+		f.getFeed(node, lang, version)
+	}
+
+	if variation.Filename == "" || variation.Draft || isHidden(node, lang, version) || !hasContent(node, lang, version) {
+		return
+	}
+
+	for _, ancestor := range getAncestors(node) {
+
+		if f.getContent(ancestor, lang, version) == "" {
+			continue
+		}
+
+		ff := f.getFeed(ancestor, lang, version)
+		link := getAbsoluteLink(node, lang, version)
+		if ff.written[link] {
+			continue
+		}
+		ff.written[link] = true
+
+		entry := &feedEntry{
+			title:     variation.Title,
+			url:       link,
+			summary:   variation.Description,
+			image:     image,
+			authors:   variation.FrontMatter.Authors,
+			tags:      variation.FrontMatter.Tags,
+			published: getDate(node, variation),
+			updated:   getLastmod(variation.Filename),
+		}
+		if entry.updated.Before(entry.published) {
+			entry.updated = entry.published
+		}
+		if ff.content == feedFull {
+			entry.content = getAbsoluteHtml(content, link)
+		}
+
+		ff.entries = append(ff.entries, entry)
+	}
+}
+
+// links returns the feeds of a page and its ancestors, none without the site
+// url, feeds are not written then
+func (f *Feeds) links(node *Node, lang, version string) []*FeedLink {
+
+	if baseurl == "" {
+		return nil
+	}
+
+	result := []*FeedLink{}
+
+	for _, n := range append(getAncestors(node), node) {
+		if f.getContent(n, lang, version) == "" {
+			continue
+		}
+		dir := path.Dir(getLink(n, lang, version))
+		title := newNavItem(n, lang, version).Title
+		result = append(result,
+			&FeedLink{Type: "application/atom+xml", Title: title, Url: path.Join(dir, atomFile)},
+			&FeedLink{Type: "application/rss+xml", Title: title, Url: path.Join(dir, rssFile)},
+			&FeedLink{Type: "application/feed+json", Title: title, Url: path.Join(dir, jsonFeedFile)},
+		)
+	}
+
+	return result
+}
+
+// getAbsoluteHtml makes the links and images of content absolute, feed
+// readers show it out of the site. The title is left out, readers already
+// show it.
+func getAbsoluteHtml(content, link string) string {
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	b := &bytes.Buffer{}
+	for _, n := range nodes {
+		if n.DataAtom == atom.H1 {
+			continue
+		}
+		traverseHtml(n, func(node *html.Node) {
+			for _, key := range []string{"href", "src"} {
+				if value := getAttribute(node, key); value != "" {
+					setAttribute(node, key, getAbsoluteUrl(value, link))
+				}
+			}
+		})
+		html.Render(b, n)
+	}
+
+	return b.String()
+}
+
+// write writes atom.xml, rss.xml and feed.json of every feed section
+func (f *Feeds) write(www string) {
+
+	if len(f.feeds) == 0 {
+		return
+	}
+
+	if baseurl == "" {
+		fmt.Println("WARNING: feeds are not generated, they need the site url (--url)")
+		return
+	}
+
+	for _, ff := range f.feeds {
+
+		slices.SortStableFunc(ff.entries, func(a, b *feedEntry) int {
+			return b.published.Compare(a.published) // newest first
+		})
+
+		dir := path.Join(www, ff.dir)
+		err := os.MkdirAll(dir, 0777)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		writeXML(path.Join(dir, atomFile), ff.atom())
+		writeXML(path.Join(dir, rssFile), ff.rss())
+		writeJSON(path.Join(dir, jsonFeedFile), ff.jsonFeed())
+	}
+}
+
+func (ff *feed) feedUrl(name string) string {
+	return strings.TrimSuffix(baseurl, "/") + path.Join(basepath, ff.dir, name)
+}
+
+func (ff *feed) updated() time.Time {
+	updated := time.Time{}
+	for _, entry := range ff.entries {
+		if entry.updated.After(updated) {
+			updated = entry.updated
+		}
+	}
+	if updated.IsZero() {
+		return now
+	}
+	return updated
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Lang    string       `xml:"xml:lang,attr"`
+	Title   string       `xml:"title"`
+	Id      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	Id        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Authors   []atomAuthor `xml:"author"`
+	Summary   *atomText    `xml:"summary"`
+	Content   *atomText    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+func (ff *feed) atom() *atomFeed {
+
+	result := &atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Lang:    ff.language,
+		Title:   ff.title,
+		Id:      ff.url,
+		Updated: ff.updated().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: ff.feedUrl(atomFile)},
+			{Rel: "alternate", Type: "text/html", Href: ff.url},
+		},
+	}
+
+	for _, entry := range ff.entries {
+		e := &atomEntry{
+			Title:     entry.title,
+			Id:        entry.url,
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: entry.url},
+			Published: entry.published.Format(time.RFC3339),
+			Updated:   entry.updated.Format(time.RFC3339),
+		}
+		for _, author := range entry.authors {
+			e.Authors = append(e.Authors, atomAuthor{Name: author})
+		}
+		if entry.summary != "" {
+			e.Summary = &atomText{Type: "text", Text: entry.summary}
+		}
+		if entry.content != "" {
+			e.Content = &atomText{Type: "html", Text: entry.content}
+		}
+		result.Entries = append(result.Entries, e)
+	}
+
+	return result
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Self          atomLink   `xml:"atom:link"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	Content     string   `xml:"content:encoded,omitempty"`
+}
+
+func (ff *feed) rss() *rssFeed {
+
+	description := ff.description
+	if description == "" {
+		description = ff.title // required by rss
+	}
+
+	result := &rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         ff.title,
+			Link:          ff.url,
+			Description:   description,
+			Language:      ff.language,
+			LastBuildDate: ff.updated().Format(time.RFC1123Z),
+			Self:          atomLink{Rel: "self", Type: "application/rss+xml", Href: ff.feedUrl(rssFile)},
+		},
+	}
+
+	for _, entry := range ff.entries {
+		result.Channel.Items = append(result.Channel.Items, &rssItem{
+			Title:       entry.title,
+			Link:        entry.url,
+			Guid:        entry.url,
+			PubDate:     entry.published.Format(time.RFC1123Z),
+			Description: entry.summary,
+			Categories:  entry.tags,
+			Content:     entry.content,
+		})
+	}
+
+	return result
+}
+
+type jsonFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageUrl string          `json:"home_page_url"`
+	FeedUrl     string          `json:"feed_url"`
+	Description string          `json:"description,omitempty"`
+	Language    string          `json:"language"`
+	Items       []*jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	Id            string           `json:"id"`
+	Url           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHtml   string           `json:"content_html,omitempty"`
+	ContentText   string           `json:"content_text,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func (ff *feed) jsonFeed() *jsonFeed {
+
+	result := &jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       ff.title,
+		HomePageUrl: ff.url,
+		FeedUrl:     ff.feedUrl(jsonFeedFile),
+		Description: ff.description,
+		Language:    ff.language,
+		Items:       []*jsonFeedItem{},
+	}
+
+	for _, entry := range ff.entries {
+		item := &jsonFeedItem{
+			Id:            entry.url,
+			Url:           entry.url,
+			Title:         entry.title,
+			ContentHtml:   entry.content,
+			Summary:       entry.summary,
+			Image:         entry.image,
+			DatePublished: entry.published.Format(time.RFC3339),
+			DateModified:  entry.updated.Format(time.RFC3339),
+			Tags:          entry.tags,
+		}
+		if item.ContentHtml == "" {
+			item.ContentText = cmp.Or(entry.summary, entry.title) // items need content
+		}
+		for _, author := range entry.authors {
+			item.Authors = append(item.Authors, jsonFeedAuthor{Name: author})
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	return result
+}
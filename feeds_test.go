@@ -0,0 +1,47 @@
+package holadoc
+
+import "testing"
+
+func TestFeedsLinks(t *testing.T) {
+
+	defer func(l, v []string, u string) { languages, versions, baseurl = l, v, u }(languages, versions, baseurl)
+	languages = []string{"en"}
+	versions = []string{}
+
+	root := &Node{}
+	news := &Node{Name: "news", Parent: root, Variations: []*Variation{
+		{Title: "News", Language: "en", FrontMatter: &FrontMatter{}},
+	}}
+	release := &Node{Name: "release", Parent: news, Variations: []*Variation{
+		{Title: "Release", Language: "en", FrontMatter: &FrontMatter{}},
+	}}
+	root.Children = []*Node{news}
+	news.Children = []*Node{release}
+
+	feeds := newFeeds(&Site{Feeds: map[string]any{"news": true}})
+
+	cases := []struct {
+		baseurl string
+		node    *Node
+		want    []string
+	}{
+		{"https://hola.cloud", release, []string{"/news/atom.xml", "/news/rss.xml", "/news/feed.json"}},
+		{"https://hola.cloud", news, []string{"/news/atom.xml", "/news/rss.xml", "/news/feed.json"}},
+		{"https://hola.cloud", root, nil},
+		{"", release, nil}, // feeds are not written without the site url
+	}
+
+	for _, c := range cases {
+		baseurl = c.baseurl
+		links := feeds.links(c.node, "en", "")
+		if len(links) != len(c.want) {
+			t.Errorf("links(%q) with url %q has %d links, want %d", c.node.Name, c.baseurl, len(links), len(c.want))
+			continue
+		}
+		for i, link := range links {
+			if link.Url != c.want[i] {
+				t.Errorf("links(%q) with url %q [%d] = %q, want %q", c.node.Name, c.baseurl, i, link.Url, c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package holadoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the optional metadata at the beginning of a source file. It
+// can be written in YAML (between `---`), TOML (between `+++`) or JSON (a
+// single object starting at the first byte).
+//
+// Values declared in front matter take precedence over the name convention.
+type FrontMatter struct {
+	Title       string
+	Description string
+	Slug        string
+	Order       *int
+	Language    string
+	Version     string
+	Draft       bool
+	PublishDate time.Time
+	ExpiryDate  time.Time
+	Hidden      bool   // reachable but not listed in the tree, children or prev/next
+	Link        string // external url, the node is only an entry of the tree
+	Separator   bool   // section header in the tree, not a page
+	Template    string
+	Layout      string
+	Tags        []string
+	Categories  []string
+	Aliases     []string
+	Authors     []string
+	Image       string   // shared in social networks, defaults to the first image
+	Type        string   // "blog" for blog sections and posts, see Blogs and getArticleType
+	Feed        string   // "full" or "summary" for sections publishing feeds, see Feeds
+	Menus       []string // names of the menus that include the page
+	Weight      int      // position in menus
+	Params      map[string]any
+}
+
+var frontMatterKeys = []string{
+	"title", "description", "slug", "order", "lang", "language", "version",
+	"draft", "publishdate", "date", "expirydate", "hidden", "link", "separator",
+	"template", "layout",
+	"tags", "categories", "aliases", "authors", "image", "type", "feed", "menu", "weight", "params",
+}
+
+// readSource reads a source file (.md or .html) and returns its front matter
+// and its content as html
+func readSource(filename string) (*FrontMatter, io.Reader) {
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	frontMatter, body, err := parseFrontMatter(src)
+	if err != nil {
+		panic(filename + ": " + err.Error())
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".md":
+		return frontMatter, md2html(body)
+	}
+
+	return frontMatter, bytes.NewReader(body)
+}
+
+// readDescriptor reads a `.link` file, a node without content described only
+// by front matter keys written in YAML or JSON:
+//
+//	title: GitHub
+//	link: https://github.com/fulldump/holadoc
+func readDescriptor(filename string) *FrontMatter {
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	raw := map[string]any{}
+	err = yaml.Unmarshal(src, &raw)
+	if err != nil {
+		panic(filename + ": " + err.Error())
+	}
+
+	return newFrontMatter(raw)
+}
+
+// parseFrontMatter splits a source file into front matter and body, files
+// without front matter return an empty FrontMatter
+func parseFrontMatter(src []byte) (*FrontMatter, []byte, error) {
+
+	raw := map[string]any{}
+	body := src
+
+	trimmed := bytes.TrimPrefix(src, []byte("\xef\xbb\xbf")) // utf-8 bom
+
+	switch {
+	case hasDelimiter(trimmed, "---"):
+		header, rest, ok := splitFrontMatter(trimmed, "---")
+		if !ok {
+			break
+		}
+		err := yaml.Unmarshal(header, &raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("yaml front matter: %w", err)
+		}
+		body = rest
+
+	case hasDelimiter(trimmed, "+++"):
+		header, rest, ok := splitFrontMatter(trimmed, "+++")
+		if !ok {
+			break
+		}
+		err := toml.Unmarshal(header, &raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("toml front matter: %w", err)
+		}
+		body = rest
+
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		err := decoder.Decode(&raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("json front matter: %w", err)
+		}
+		body = trimmed[decoder.InputOffset():]
+	}
+
+	return newFrontMatter(raw), body, nil
+}
+
+func hasDelimiter(src []byte, delimiter string) bool {
+	return bytes.HasPrefix(src, []byte(delimiter+"\n")) || bytes.HasPrefix(src, []byte(delimiter+"\r\n"))
+}
+
+func splitFrontMatter(src []byte, delimiter string) (header, body []byte, ok bool) {
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		if string(bytes.TrimRight(line, "\r\n")) == delimiter {
+			return src[len(lines[0]):offset], src[offset+len(line):], true
+		}
+		offset += len(line)
+	}
+	return nil, src, false
+}
+
+func newFrontMatter(raw map[string]any) *FrontMatter {
+
+	// keys are case insensitive: publishDate == publishdate
+	for k, v := range raw {
+		if lower := strings.ToLower(k); lower != k {
+			delete(raw, k)
+			raw[lower] = v
+		}
+	}
+
+	f := &FrontMatter{
+		Title:       asString(raw["title"]),
+		Description: asString(raw["description"]),
+		Slug:        asString(raw["slug"]),
+		Language:    strings.ToLower(asString(raw["lang"])),
+		Version:     strings.ToLower(asString(raw["version"])),
+		Draft:       asBool(raw["draft"]),
+		Hidden:      asBool(raw["hidden"]),
+		Link:        asString(raw["link"]),
+		Separator:   asBool(raw["separator"]),
+		Template:    asString(raw["template"]),
+		Layout:      asString(raw["layout"]),
+		Tags:        asStrings(raw["tags"]),
+		Categories:  asStrings(raw["categories"]),
+		Aliases:     asStrings(raw["aliases"]),
+		Authors:     asStrings(raw["authors"]),
+		Image:       asString(raw["image"]),
+		Type:        strings.ToLower(asString(raw["type"])),
+		Feed:        asFeed(raw["feed"]),
+		Menus:       asStrings(raw["menu"]),
+		Params:      map[string]any{},
+	}
+
+	if f.Language == "" {
+		f.Language = strings.ToLower(asString(raw["language"]))
+	}
+
+	f.PublishDate, _ = asTime(raw["publishdate"])
+	if f.PublishDate.IsZero() {
+		f.PublishDate, _ = asTime(raw["date"]) // usual in blogs
+	}
+	f.ExpiryDate, _ = asTime(raw["expirydate"])
+
+	f.Weight, _ = asInt(raw["weight"])
+
+	if order, ok := asInt(raw["order"]); ok {
+		f.Order = &order
+	}
+
+	if params, ok := raw["params"].(map[string]any); ok {
+		for k, v := range params {
+			f.Params[strings.ToLower(k)] = v // like the other keys
+		}
+	}
+
+	// unknown keys are also available as params
+	for k, v := range raw {
+		if !in(frontMatterKeys, k) {
+			f.Params[k] = v
+		}
+	}
+
+	return f
+}
+
+func asString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+func asBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+func asInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		i, err := strconv.Atoi(v)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func asTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		for _, layout := range timeLayouts {
+			t, err := time.ParseInLocation(layout, strings.TrimSpace(v), time.Local)
+			if err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// isPublished returns false for drafts, pages scheduled for the future and
+// expired pages
+func (f *FrontMatter) isPublished(now time.Time) bool {
+	if f.Draft {
+		return false
+	}
+	if !f.PublishDate.IsZero() && f.PublishDate.After(now) {
+		return false
+	}
+	if !f.ExpiryDate.IsZero() && !f.ExpiryDate.After(now) {
+		return false
+	}
+	return true
+}
+
+// asStrings accepts both a list and a comma separated string
+func asStrings(v any) []string {
+	result := []string{}
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if s := strings.TrimSpace(asString(item)); s != "" {
+				result = append(result, s)
+			}
+		}
+	case []string:
+		result = append(result, v...)
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if s := strings.TrimSpace(item); s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package holadoc
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		src   string
+		title string
+		tags  []string
+		order int // 0 is no order
+		body  string
+	}{
+		{"none", "# Hello\n", "", []string{}, 0, "# Hello\n"},
+		{"yaml", "---\ntitle: Hello\ntags: [a, b]\norder: 3\n---\n# Hello\n", "Hello", []string{"a", "b"}, 3, "# Hello\n"},
+		{"yaml crlf", "---\r\ntitle: Hello\r\n---\r\nbody", "Hello", []string{}, 0, "body"},
+		{"yaml bom", "\xef\xbb\xbf---\ntitle: Hello\n---\nbody", "Hello", []string{}, 0, "body"},
+		{"yaml not closed", "---\ntitle: Hello\n", "", []string{}, 0, "---\ntitle: Hello\n"},
+		{"toml", "+++\ntitle = \"Hello\"\ntags = [\"a\"]\norder = 2\n+++\nbody", "Hello", []string{"a"}, 2, "body"},
+		{"json", "{\"title\": \"Hello\", \"tags\": \"a, b\", \"order\": 1}\nbody", "Hello", []string{"a", "b"}, 1, "\nbody"},
+		{"keys ignore case", "---\nTitle: Hello\nTAGS: a\n---\n", "Hello", []string{"a"}, 0, ""},
+	}
+
+	for _, c := range cases {
+		f, body, err := parseFrontMatter([]byte(c.src))
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.name, err)
+			continue
+		}
+		if f.Title != c.title {
+			t.Errorf("%s: title is %q, want %q", c.name, f.Title, c.title)
+		}
+		if !slices.Equal(f.Tags, c.tags) {
+			t.Errorf("%s: tags are %q, want %q", c.name, f.Tags, c.tags)
+		}
+		order := 0
+		if f.Order != nil {
+			order = *f.Order
+		}
+		if order != c.order {
+			t.Errorf("%s: order is %d, want %d", c.name, order, c.order)
+		}
+		if string(body) != c.body {
+			t.Errorf("%s: body is %q, want %q", c.name, body, c.body)
+		}
+	}
+}
+
+func TestParseFrontMatterErrors(t *testing.T) {
+
+	cases := []string{
+		"---\ntitle: [\n---\n",
+		"+++\ntitle = \n+++\n",
+		"{\"title\": ",
+	}
+
+	for _, src := range cases {
+		if _, _, err := parseFrontMatter([]byte(src)); err == nil {
+			t.Errorf("parseFrontMatter(%q) should fail", src)
+		}
+	}
+}
+
+func TestFrontMatterParams(t *testing.T) {
+
+	f, _, err := parseFrontMatter([]byte("---\nparams:\n  Color: blue\nIcon: star\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"color": "blue", "icon": "star"}
+	for k, v := range want {
+		if f.Params[k] != v {
+			t.Errorf("params[%q] is %v, want %v", k, f.Params[k], v)
+		}
+	}
+}
+
+func TestAsTime(t *testing.T) {
+
+	cases := []struct {
+		value any
+		want  time.Time
+		ok    bool
+	}{
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), true},
+		{" 2024-05-01 ", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), true},
+		{"2024-05-01 10:30", time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local), true},
+		{"2024-05-01 10:30:15", time.Date(2024, 5, 1, 10, 30, 15, 0, time.Local), true},
+		{"2024-05-01T10:30:15", time.Date(2024, 5, 1, 10, 30, 15, 0, time.Local), true},
+		{"2024-05-01T10:30:15Z", time.Date(2024, 5, 1, 10, 30, 15, 0, time.UTC), true},
+		{"2024-05-01T10:30:15+02:00", time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC), true},
+		{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), true},
+		{"01/05/2024", time.Time{}, false},
+		{"", time.Time{}, false},
+		{nil, time.Time{}, false},
+		{20240501, time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		got, ok := asTime(c.value)
+		if ok != c.ok || !got.Equal(c.want) {
+			t.Errorf("asTime(%#v) = %s, %v, want %s, %v", c.value, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFrontMatterDates(t *testing.T) {
+
+	cases := []struct {
+		src  string
+		want time.Time
+	}{
+		{"---\ndate: 2024-05-01\n---\n", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"---\npublishDate: 2024-05-01T10:00:00Z\ndate: 2020-01-01\n---\n", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"+++\ndate = 2024-05-01T10:00:00Z\n+++\n", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"{\"date\": \"2024-05-01T10:00:00Z\"}", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		f, _, err := parseFrontMatter([]byte(c.src))
+		if err != nil {
+			t.Errorf("parseFrontMatter(%q): %s", c.src, err)
+			continue
+		}
+		if !f.PublishDate.Equal(c.want) {
+			t.Errorf("parseFrontMatter(%q) publish date is %s, want %s", c.src, f.PublishDate, c.want)
+		}
+	}
+}
+
+func TestIsPublished(t *testing.T) {
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		f    FrontMatter
+		want bool
+	}{
+		{"no dates", FrontMatter{}, true},
+		{"draft", FrontMatter{Draft: true}, false},
+		{"published", FrontMatter{PublishDate: now.Add(-time.Hour)}, true},
+		{"scheduled", FrontMatter{PublishDate: now.Add(time.Hour)}, false},
+		{"expired", FrontMatter{ExpiryDate: now.Add(-time.Hour)}, false},
+		{"expires now", FrontMatter{ExpiryDate: now}, false},
+		{"not expired", FrontMatter{ExpiryDate: now.Add(time.Hour)}, true},
+	}
+
+	for _, c := range cases {
+		if got := c.f.isPublished(now); got != c.want {
+			t.Errorf("%s: isPublished is %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package holadoc
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"text/template/parse"
+)
+
+// renderContext is a node being rendered for a language and version, it
+// provides the functions available in templates
+type renderContext struct {
+	root       *Node
+	node       *Node
+	language   string
+	content    string // language of the content, see contentLanguage
+	version    string
+	menus      map[string][]*MenuEntry
+	taxonomies Taxonomies
+	www        string
+
+	// template being executed, used to locate diagnostics
+	template *template.Template
+}
+
+func (r *renderContext) funcs() template.FuncMap {
+	return template.FuncMap{
+		"link": func(p any) template.HTML {
+
+			target := r.resolve("link", p)
+			if target == nil {
+				return ""
+			}
+
+			class := "link"
+			if target.Node == r.node {
+				class += " selected"
+			}
+
+			return template.HTML(`<a class="` + class + `" href="` + template.HTMLEscapeString(target.Url) + `">` + template.HTMLEscapeString(target.Title) + `</a>`)
+		},
+
+		"tree": func(p any, options ...any) template.HTML {
+
+			items := r.treeItems("tree", p, options)
+			if items == nil || r.template == nil {
+				return ""
+			}
+
+			b := &bytes.Buffer{}
+			err := r.template.ExecuteTemplate(b, "tree", items)
+			if err != nil {
+				r.diagnostic("tree", "", "tree: "+err.Error())
+				return ""
+			}
+
+			return template.HTML(b.String())
+		},
+
+		"treeItems": func(p any, options ...any) []*NavItem {
+			return r.treeItems("treeItems", p, options)
+		},
+
+		"treeFragment": func(p any) string {
+
+			target := r.resolveNode("treeFragment", p)
+			if target == nil || r.template == nil {
+				return ""
+			}
+
+			temp := r.template.Lookup("tree")
+			if temp == nil {
+				r.diagnostic("treeFragment", "", "treeFragment: template 'tree' is not defined")
+				return ""
+			}
+
+			return writeTreeFragment(r.www, temp, target, r.language, r.version)
+		},
+
+		"menu": func(name string) []*NavItem {
+			return getMenu(r.menus[name], r.node, r.language, r.version)
+		},
+
+		"searchIndex": func() string {
+			return getSearchIndexLink(r.language, r.version)
+		},
+
+		"arrow": func() string {
+			return getArrow(cmp.Or(r.content, r.language))
+		},
+
+		"t": func(key string, args ...any) string {
+			message := translate(r.language, key)
+			if len(args) > 0 {
+				message = fmt.Sprintf(message, args...)
+			}
+			return message
+		},
+
+		"isUnder": func(p any) bool {
+
+			target := r.resolveNode("isUnder", p)
+			if target == nil {
+				return false
+			}
+
+			return isUnder(r.node, target)
+		},
+
+		"page": func(p any) *Page {
+			return r.resolve("page", p)
+		},
+
+		"exists": func(p any) bool {
+			switch p := p.(type) {
+			case string:
+				return getNode(r.root, p) != nil
+			case *Page:
+				return p != nil
+			}
+			return false
+		},
+
+		"parent": func(p ...any) *Page {
+			n := r.resolveNode("parent", p...)
+			if n == nil {
+				return nil
+			}
+			return r.newPage(getParent(n))
+		},
+
+		"children": func(p ...any) []*Page {
+			n := r.resolveNode("children", p...)
+			if n == nil {
+				return nil
+			}
+			return r.listed(r.newPages(getChildren(n)))
+		},
+
+		"siblings": func(p ...any) []*Page {
+			n := r.resolveNode("siblings", p...)
+			if n == nil {
+				return nil
+			}
+			parent := getParent(n)
+			if parent == nil {
+				return nil
+			}
+			siblings := []*Node{}
+			for _, child := range getChildren(parent) {
+				if child != n {
+					siblings = append(siblings, child)
+				}
+			}
+			return r.listed(r.newPages(siblings))
+		},
+
+		"ancestors": func(p ...any) []*Page {
+			n := r.resolveNode("ancestors", p...)
+			if n == nil {
+				return nil
+			}
+			return r.newPages(getAncestors(n))
+		},
+
+		"prev": func(p ...any) *Page {
+			n := r.resolveNode("prev", p...)
+			if n == nil {
+				return nil
+			}
+			prev, _ := getPrevNext(r.root, n, r.language, r.version)
+			return r.newPage(prev)
+		},
+
+		"next": func(p ...any) *Page {
+			n := r.resolveNode("next", p...)
+			if n == nil {
+				return nil
+			}
+			_, next := getPrevNext(r.root, n, r.language, r.version)
+			return r.newPage(next)
+		},
+
+		"terms": func(taxonomy string, p ...any) []*Term {
+			if !r.isTaxonomy("terms", taxonomy) {
+				return nil
+			}
+			n := r.resolveNode("terms", p...)
+			if n == nil {
+				return nil
+			}
+			variation := getBestVariation(n.Variations, r.language, r.version)
+			if variation == nil {
+				return nil
+			}
+			result := []*Term{}
+			for _, name := range getTerms(variation.FrontMatter, taxonomy) {
+				if term := r.taxonomies.find(r.language, taxonomy, name); term != nil && !slices.Contains(result, term) {
+					result = append(result, term)
+				}
+			}
+			return result
+		},
+
+		"taxonomy": func(taxonomy string) []*Term {
+			if !r.isTaxonomy("taxonomy", taxonomy) {
+				return nil
+			}
+			return r.taxonomies[r.language][taxonomy]
+		},
+
+		"termPages": func(taxonomy, name string) []*Page {
+			if !r.isTaxonomy("termPages", taxonomy) {
+				return nil
+			}
+			term := r.taxonomies.find(r.language, taxonomy, name)
+			if term == nil {
+				return []*Page{}
+			}
+			return term.pages(r.node, r.language)
+		},
+
+		"where":  wherePages,
+		"sortBy": sortPages,
+		"first":  firstPages,
+
+		"relLink": func(p any) string {
+			target := r.resolve("relLink", p)
+			if target == nil {
+				return ""
+			}
+			return relativeLink(getLink(r.node, r.language, r.version), target.Url)
+		},
+
+		"absLink": func(p any) string {
+			target := r.resolve("absLink", p)
+			if target == nil {
+				return ""
+			}
+			return getAbsoluteLink(target.Node, r.language, r.version)
+		},
+	}
+}
+
+// resolve returns the page for a path or a page, missing paths are reported
+// as diagnostics
+func (r *renderContext) resolve(fn string, p any) *Page {
+	return r.newPage(r.resolveNode(fn, p))
+}
+
+// resolveNode accepts nothing (the current node), a path or a page
+func (r *renderContext) resolveNode(fn string, args ...any) *Node {
+
+	if len(args) == 0 {
+		return r.node
+	}
+
+	switch p := args[0].(type) {
+	case string:
+		n := getNode(r.root, p)
+		if n == nil {
+			r.diagnostic(fn, p, fmt.Sprintf("%s: '%s' does not exist", fn, p))
+		}
+		return n
+	case *Page:
+		if p == nil {
+			return nil
+		}
+		return p.Node
+	case *NavItem:
+		if p == nil {
+			return nil
+		}
+		return p.Node
+	case nil:
+		return nil
+	}
+
+	r.diagnostic(fn, "", fmt.Sprintf("%s: unexpected argument %T", fn, args[0]))
+	return nil
+}
+
+// treeItems returns the tree below p with the options of `tree`
+func (r *renderContext) treeItems(fn string, p any, options []any) []*NavItem {
+
+	target := r.resolveNode(fn, p)
+	if target == nil {
+		return nil
+	}
+
+	treeOptions, err := parseTreeOptions(options)
+	if err != nil {
+		r.diagnostic(fn, "", fn+": "+err.Error())
+	}
+
+	return getTree(target, r.node, r.language, r.version, treeOptions)
+}
+
+func (r *renderContext) isTaxonomy(fn, taxonomy string) bool {
+	if !in(taxonomyNames, taxonomy) {
+		r.diagnostic(fn, taxonomy, fmt.Sprintf("%s: unknown taxonomy '%s', use %s", fn, taxonomy, strings.Join(taxonomyNames, " or ")))
+		return false
+	}
+	return true
+}
+
+func (r *renderContext) newPage(n *Node) *Page {
+	return newPage(n, r.node, r.language, r.version)
+}
+
+func (r *renderContext) newPages(nodes []*Node) []*Page {
+	result := []*Page{}
+	for _, n := range nodes {
+		if page := r.newPage(n); page != nil {
+			result = append(result, page)
+		}
+	}
+	return result
+}
+
+// listed removes hidden pages, the current page is kept
+func (r *renderContext) listed(pages []*Page) []*Page {
+	result := []*Page{}
+	for _, page := range pages {
+		if !page.Hidden || page.Selected {
+			result = append(result, page)
+		}
+	}
+	return result
+}
+
+// diagnostics already printed, the same template is executed for many pages
+var diagnostics = map[string]bool{}
+
+// diagnostic prints a warning pointing to the template call of fn with the
+// literal argument arg, or to the template file if the call is not found
+func (r *renderContext) diagnostic(fn, arg, message string) {
+
+	location := ""
+	if r.template != nil {
+		location = r.template.Name()
+		for _, t := range r.template.Templates() {
+			if t.Tree == nil {
+				continue
+			}
+			if n := findCall(t.Tree.Root, fn, arg); n != nil {
+				location, _ = t.Tree.ErrorContext(n)
+				break
+			}
+		}
+	}
+
+	warnOnce(fmt.Sprintf("WARNING: %s: %s", location, message))
+}
+
+// warnOnce prints a warning the first time it happens in a build
+func warnOnce(warning string) {
+	if diagnostics[warning] {
+		return
+	}
+	diagnostics[warning] = true
+
+	fmt.Println(warning)
+}
+
+// findCall looks for a call to fn with a string literal arg in a template tree
+func findCall(n parse.Node, fn, arg string) parse.Node {
+
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if found := findCall(child, fn, arg); found != nil {
+				return found
+			}
+		}
+	case *parse.ActionNode:
+		return findCall(n.Pipe, fn, arg)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if found := findCall(cmd, fn, arg); found != nil {
+				return found
+			}
+		}
+	case *parse.CommandNode:
+		if len(n.Args) > 0 {
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == fn {
+				for _, a := range n.Args[1:] {
+					if s, ok := a.(*parse.StringNode); ok && (arg == "" || s.Text == arg) {
+						return n
+					}
+				}
+			}
+		}
+		for _, a := range n.Args {
+			if found := findCall(a, fn, arg); found != nil {
+				return found
+			}
+		}
+	case *parse.IfNode:
+		return findCall(&n.BranchNode, fn, arg)
+	case *parse.RangeNode:
+		return findCall(&n.BranchNode, fn, arg)
+	case *parse.WithNode:
+		return findCall(&n.BranchNode, fn, arg)
+	case *parse.BranchNode:
+		for _, child := range []parse.Node{n.Pipe, n.List, n.ElseList} {
+			if found := findCall(child, fn, arg); found != nil {
+				return found
+			}
+		}
+	case *parse.TemplateNode:
+		return findCall(n.Pipe, fn, arg)
+	}
+
+	return nil
+}
+
+func isUnder(n, target *Node) bool {
+	for n != nil {
+		if n == target {
+			return true
+		}
+		n = n.Parent
+	}
+	return false
+}
+
+// relativeLink returns the link to target relative to the page at from
+func relativeLink(from, target string) string {
+
+	fromParts := strings.Split(strings.TrimPrefix(from, "/"), "/")
+	targetParts := strings.Split(strings.TrimPrefix(target, "/"), "/")
+
+	// the last part of from is the file itself
+	fromParts = fromParts[:len(fromParts)-1]
+
+	common := 0
+	for common < len(fromParts) && common < len(targetParts)-1 && fromParts[common] == targetParts[common] {
+		common++
+	}
+
+	result := []string{}
+	for range fromParts[common:] {
+		result = append(result, "..")
+	}
+	result = append(result, targetParts[common:]...)
+
+	return strings.Join(result, "/")
+}
@@ -0,0 +1,95 @@
+package holadoc
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestTreeFuncs(t *testing.T) {
+
+	defer func(l, v []string) { languages, versions = l, v }(languages, versions)
+	languages = []string{"en"}
+	versions = []string{}
+
+	root := &Node{}
+	docs := &Node{Name: "docs", Parent: root}
+	intro := &Node{Name: "intro", Parent: docs, Variations: []*Variation{
+		{Title: "Intro", Language: "en", FrontMatter: &FrontMatter{}},
+	}}
+	root.Children = []*Node{docs}
+	docs.Children = []*Node{intro}
+
+	ctx := &renderContext{root: root, node: intro, language: "en"}
+	temp := template.Must(template.New("page").Funcs(ctx.funcs()).Parse(partials +
+		`{{ tree "docs" }}|{{ range treeItems "docs" }}{{ .Title }}{{ end }}`))
+	ctx.template = temp
+
+	b := &strings.Builder{}
+	err := temp.Execute(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html, items, _ := strings.Cut(b.String(), "|")
+	if !strings.Contains(html, `>Intro</a>`) {
+		t.Errorf("tree \"docs\" = %q, want the html of the tree partial", html)
+	}
+	if items != "Intro" {
+		t.Errorf("treeItems \"docs\" titles = %q, want %q", items, "Intro")
+	}
+}
+
+func TestQueryFuncs(t *testing.T) {
+
+	defer func(l, v []string, d map[string]bool) { languages, versions, diagnostics = l, v, d }(languages, versions, diagnostics)
+	languages = []string{"en"}
+	versions = []string{}
+	diagnostics = map[string]bool{}
+
+	variation := func(title string, frontMatter *FrontMatter) *Variation {
+		return &Variation{Title: title, Language: "en", Filename: title + ".md", FrontMatter: frontMatter}
+	}
+	root := &Node{}
+	docs := &Node{Name: "docs", Parent: root, Variations: []*Variation{variation("Docs", &FrontMatter{})}}
+	install := &Node{Name: "install", Parent: docs, Variations: []*Variation{variation("Install", &FrontMatter{})}}
+	config := &Node{Name: "config", Parent: docs, Variations: []*Variation{variation("Config", &FrontMatter{})}}
+	secret := &Node{Name: "secret", Parent: docs, Variations: []*Variation{variation("Secret", &FrontMatter{Hidden: true})}}
+	root.Children = []*Node{docs}
+	docs.Children = []*Node{install, config, secret}
+
+	cases := []struct {
+		template string
+		want     string
+	}{
+		{`{{ (parent).Title }}`, "Docs"},
+		{`{{ range children "docs" }}{{ .Title }},{{ end }}`, "Install,Config,"}, // hidden pages are not listed
+		{`{{ range siblings }}{{ .Title }},{{ end }}`, "Config,"},
+		{`{{ range ancestors }}{{ .Title }},{{ end }}`, "Docs,"},
+		{`{{ (page "docs/secret").Title }}`, "Secret"},
+		{`{{ exists "docs/config" }} {{ exists "docs/missing" }}`, "true false"},
+		{`{{ isUnder "docs" }}`, "true"},
+		{`{{ with page "docs/missing" }}{{ .Title }}{{ end }}`, ""}, // reported as a diagnostic
+		{`{{ range first 1 (sortBy (children "docs") "Title") }}{{ .Title }}{{ end }}`, "Config"},
+	}
+
+	for _, c := range cases {
+		ctx := &renderContext{root: root, node: install, language: "en"}
+		temp := template.Must(template.New("page").Funcs(ctx.funcs()).Parse(c.template))
+		ctx.template = temp
+
+		b := &strings.Builder{}
+		err := temp.Execute(b, nil)
+		if err != nil {
+			t.Errorf("%s: %s", c.template, err)
+			continue
+		}
+		if b.String() != c.want {
+			t.Errorf("%s = %q, want %q", c.template, b.String(), c.want)
+		}
+	}
+
+	if len(diagnostics) != 1 {
+		t.Errorf("diagnostics = %v, want only the missing page", diagnostics)
+	}
+}
@@ -3,10 +3,13 @@ module github.com/fulldump/holadoc
 go 1.22.0
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/alecthomas/chroma v0.10.0
 	github.com/fulldump/goconfig v1.6.1
 	github.com/yuin/goldmark v1.7.8
 	golang.org/x/net v0.33.0
+	golang.org/x/text v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/dlclark/regexp2 v1.11.4 // indirect
@@ -9,10 +9,11 @@ import (
 	"net/url"
 	"os"
 	"path"
-	"slices"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma"
 	html2 "github.com/alecthomas/chroma/formatters/html"
@@ -26,17 +27,23 @@ import (
 )
 
 type Config struct {
-	Src       string `json:"src"`
-	Www       string `json:"www"`
-	Versions  string `json:"versions" usage:"default version is the first one"`
-	Languages string `json:"languages" usage:"default language is the first one"`
-	Serve     string `json:"serve" usage:"Address to serve files locally, example ':8080'"`
-	Version   bool   `json:"version" usage:"Display version and exit"`
+	Src          string `json:"src"`
+	Www          string `json:"www"`
+	Versions     string `json:"versions" usage:"default version is the first one"`
+	Languages    string `json:"languages" usage:"default language is the first one"`
+	Url          string `json:"url" usage:"Public url of the site, used for absolute links, example 'https://hola.cloud'"`
+	Drafts       bool   `json:"drafts" usage:"Include drafts and scheduled pages, always enabled with serve"`
+	ReadingOrder string `json:"reading_order" usage:"Scope of previous/next navigation: 'site', 'section' (top level node) or 'version' (nearest {version})"`
+	Theme        string `json:"theme" usage:"Theme directory or zip file relative to the working directory, overrides the theme in site.json (relative to src)"`
+	Serve        string `json:"serve" usage:"Address to serve files locally, example ':8080'"`
+	Version      bool   `json:"version" usage:"Display version and exit"`
 }
 
 // todo: avoid globals
 var versions []string
 var languages []string
+var drafts bool
+var now time.Time
 
 func HolaDoc(c Config) {
 
@@ -49,74 +56,102 @@ func HolaDoc(c Config) {
 
 	versions = strings.Split(c.Versions, ",")
 	languages = strings.Split(c.Languages, ",")
+	baseurl = c.Url
+	drafts = c.Drafts || c.Serve != ""
+	now = time.Now()
+	readingOrder = cmp.Or(c.ReadingOrder, "site")
+	if !in(readingOrders, readingOrder) {
+		panic("reading order '" + readingOrder + "' must be " + strings.Join(readingOrders, ", "))
+	}
+	treeFragments = map[string]bool{}
+	diagnostics = map[string]bool{}
+	searchIndexes = map[string]*SearchIndex{}
+	gitDates = getGitDates(c.Src)
 
 	root := &Node{}
+	aliases := map[string]bool{}
+
+	site := readSite(c.Src)
+	if c.Theme != "" {
+		site.Theme = c.Theme
+	}
+
+	themes = map[string]*Theme{}
+	themePath = ""
+	siteThemes := []*Theme{getDefaultTheme()}
+	if site.Theme != "" {
+		siteThemes = append(siteThemes, openTheme(site.Theme))
+		themePath, err = filepath.Abs(site.Theme)
+		if err != nil {
+			panic(err.Error())
+		}
+	}
+	for _, t := range siteThemes {
+		loadTheme(root, t, c.Www)
+	}
+	themeData := map[string]any{
+		"Name":   siteThemes[len(siteThemes)-1].Name,
+		"Params": getThemeParams(siteThemes, site.ThemeParams),
+	}
+
+	readCatalogs(os.DirFS(c.Src), "i18n", true)
 
 	readNodes(root, c.Src, c.Www)
 	root.PrettyPrint(0)
 
+	menus := getMenus(root, site.Menus)
+	sitemap := newSitemap(site)
+	feeds := newFeeds(site)
+	blogs := newBlogs(site)
+	taxonomies := getTaxonomies(root)
+	related := newRelated(site)
+
+	writer := &pageWriter{
+		www:        c.Www,
+		root:       root,
+		menus:      menus,
+		taxonomies: taxonomies,
+		themeData:  themeData,
+		feeds:      feeds,
+	}
+
 	traverseNodes(root, func(node *Node) {
 
 		for _, version := range versions {
 			for _, language := range languages {
 
 				variation := getBestVariation(node.Variations, language, version)
+				outputPath := getOutputPath(node, variation, language, version)
+
+				// sections without content for language and version list their children
+				auto := isAutoSection(node, language, version)
+				if auto {
+					variation = newSectionVariation(node, language, version)
+				}
+
 				if variation == nil {
 					fmt.Println("skip:", node.Path)
 					continue
 				}
 
-				langMenu := ""
-				{
-					langMenu += `<div class="languages">`
-					for _, l := range languages {
-						class := ""
-						if l == language {
-							class += "selected"
-						}
-						langMenu += `<a class="` + class + `" href="` + getLink(node, l, version) + `">` + l + `</a>`
-					}
-					langMenu += `</div>`
+				// blog sections list their posts
+				blog := blogs.getPaginate(node, language, version) > 0
+				if blog {
+					variation = listingVariation(variation, variation.Title)
 				}
-
-				versionMenu := ""
-				{
-					if hasVersions(node) {
-						versionMenu += `<div class="versions">`
-						for _, v := range versions {
-							class := ""
-							if v == version {
-								class += "selected"
-							}
-							versionMenu += `<a class="` + class + `" href="` + getLink(node, language, v) + `">` + v + `</a>`
-						}
-						versionMenu += `</div>`
-					}
+				if !isPage(node, language, version) {
+					continue // external links and separators have no output
 				}
 
 				onThisPage := ""
 
 				content := ""
 
-				{ // content
+				image := ""
 
-					var htmlReader io.Reader
+				if !auto { // content
 
-					switch strings.ToLower(path.Ext(variation.Filename)) {
-					case ".html":
-						src, err := os.Open(variation.Filename)
-						if err != nil {
-							panic(err.Error())
-						}
-						htmlReader = src
-					case ".md":
-						src, err := os.ReadFile(variation.Filename)
-						if err != nil {
-							panic(err.Error())
-						}
-						htmlReader = md2html(src)
-						// htmlReader = strings.NewReader("Hello, this is the new md!")
-					}
+					_, htmlReader := readSource(variation.Filename)
 
 					doc := &html.Node{
 						Type:     html.ElementNode,
@@ -193,6 +228,12 @@ func HolaDoc(c Config) {
 
 									node.RemoveChild(node.FirstChild)
 
+									// code is always left to right, even in rtl pages
+									setAttribute(node, "dir", "ltr")
+									if node.Parent != nil && node.Parent.Data == "pre" {
+										setAttribute(node.Parent, "dir", "ltr")
+									}
+
 									doc := &html.Node{
 										Type:     html.ElementNode,
 										Data:     "body",
@@ -225,83 +266,44 @@ func HolaDoc(c Config) {
 						content = b.String()
 					}
 
-				}
-
-				data := map[string]any{
-					"lang":        variation.Language,
-					"langs":       languages,
-					"langMenu":    template.HTML(langMenu),
-					"title":       variation.Title,
-					"url":         variation.Url,
-					"filename":    variation.Filename,
-					"version":     variation.Version,
-					"versions":    versions,
-					"versionMenu": template.HTML(versionMenu),
-					"tree":        template.HTML(getIndex(root, node, language, version)),
-					"breadcrumb":  template.HTML(getBreadcrumb(node, language, version)),
-					"index":       template.HTML(onThisPage),
-					"content":     template.HTML(content),
-				}
+					// fallback pages are indexed in the language they are
+					// written in, not with the analyzer of another one
+					if !isHidden(node, language, version) && isOwnLanguage(variation, language) {
+						getSearchIndex(language, version).addPage(variation.Title, getLink(node, language, version), nodes)
+					}
 
-				newFilename := path.Join(c.Www, getOutputPath(node, variation, language, version))
-				os.MkdirAll(path.Dir(newFilename), 0777) // todo: handle err
+					image = getImage(variation, nodes, getLink(node, language, version))
 
-				f, err := os.Create(newFilename)
-				if err != nil {
-					panic(err.Error())
 				}
 
-				temp := getTemplate(node, map[string]any{
-					"link": func(p string) template.HTML {
-
-						target := getNode(root, p)
-						if target == nil {
-							panic("link for '" + p + "' does not exist")
-						}
-
-						class := "link"
-						if target == node {
-							class += " selected"
-						}
-
-						variation := getBestVariation(target.Variations, language, version)
-
-						return template.HTML(`<a class="` + class + `" href="` + getLink(target, language, version) + `">` + variation.Title + `</a>`)
-					},
-
-					"tree": func(p string) template.HTML {
-
-						target := getNode(root, p)
-						if target == nil {
-							panic("link for '" + p + "' does not exist")
-						}
-
-						return template.HTML(getIndex(target, node, language, version))
-					},
+				prev, next := getPrevNext(root, node, language, version)
+
+				data := writer.data(node, variation, language, version)
+				data["social"] = getSocial(node, variation, language, version, image)
+				data["jsonld"] = getStructuredData(node, variation, language, version, image, blogs.isPost(node, language, version), data["breadcrumbItems"].([]*NavItem))
+				data["prev"] = newPage(prev, node, language, version)
+				data["next"] = newPage(next, node, language, version)
+				data["index"] = template.HTML(onThisPage)
+				data["content"] = template.HTML(content)
+				data["related"] = related.get(root, node, language, version)
+				if blog {
+					blogs.data(node, language, version, data)
+				}
 
-					"isUnder": func(p string) bool {
+				writer.write(node, variation, language, version, outputPath, data)
 
-						target := getNode(root, p)
-						if target == nil {
-							panic("link for '" + p + "' does not exist")
-						}
+				sitemap.add(node, variation, language, version)
+				feeds.add(node, variation, language, version, content, image)
 
-						n := node
-						for n != nil {
-							if n == target {
-								return true
-							}
-							n = n.Parent
+				// aliases redirect to the page written in its own language
+				if isOwnLanguage(variation, language) {
+					for _, alias := range variation.FrontMatter.Aliases {
+						if aliases[alias] {
+							continue
 						}
-
-						return false
-					},
-				})
-				temp.Execute(f, data)
-
-				err = f.Close()
-				if err != nil {
-					panic(err.Error())
+						aliases[alias] = true
+						writeRedirect(c.Www, alias, getLink(node, language, version))
+					}
 				}
 
 			}
@@ -309,32 +311,90 @@ func HolaDoc(c Config) {
 
 	})
 
+	blogs.write(root, writer)
+	writeTaxonomies(root, taxonomies, writer)
+	writeSearchIndexes(c.Www)
+	sitemap.write(c.Www)
+	feeds.write(c.Www)
+	checkSocial()
+	writeRobots(c.Src, c.Www)
 }
 
-func getTemplate(node *Node, funcs template.FuncMap) *template.Template {
+// pageWriter renders pages with the template of their node, it is shared by
+// node pages and generated pages
+type pageWriter struct {
+	www        string
+	root       *Node
+	menus      map[string][]*MenuEntry
+	taxonomies Taxonomies
+	themeData  map[string]any
+	feeds      *Feeds
+}
 
-	for node != nil {
-		if node.Template == "" {
-			node = node.Parent
-			continue
-		}
+// data returns the template data common to every page, callers add the
+// content
+func (w *pageWriter) data(node *Node, variation *Variation, language, version string) map[string]any {
+	return map[string]any{
+		"lang":        variation.Language,
+		"langName":    languageName(language),
+		"dir":         getDirection(contentLanguage(variation, language)),
+		"langs":       languages,
+		"title":       variation.Title,
+		"description": variation.Description,
+		"page":        variation.FrontMatter,
+		"theme":       w.themeData,
+		"draft":       variation.Draft,
+		"alternates":  getAlternates(node, version),
+		"url":         variation.Url,
+		"filename":    variation.Filename,
+		"version":     variation.Version,
+		"versions":    versions,
+		"feeds":       w.feeds.links(node, language, version),
+
+		// navigation as data, see navKeys for the rendered html
+		"langMenuItems":    getLanguageMenu(node, language, version),
+		"versionMenuItems": getVersionMenu(node, language, version),
+		"treeItems":        getTree(w.root, node, language, version, TreeOptions{}),
+		"breadcrumbItems":  getBreadcrumb(node, language, version),
+	}
+}
 
-		gohtml, err := os.ReadFile(node.Template)
-		if err != nil {
-			panic(err.Error())
-		}
+// write executes the template of variation and writes it to outputPath
+func (w *pageWriter) write(node *Node, variation *Variation, language, version, outputPath string, data map[string]any) {
 
-		temp, err := template.New("").Funcs(funcs).Parse(string(gohtml))
-		if err != nil {
-			panic(err.Error())
-		}
+	newFilename, err := joinWww(w.www, outputPath)
+	if err != nil {
+		panic(err.Error())
+	}
+	os.MkdirAll(path.Dir(newFilename), 0777) // todo: handle err
 
-		return temp
+	f, err := os.Create(newFilename)
+	if err != nil {
+		panic(err.Error())
 	}
 
-	panic("No template found!!!")
+	ctx := &renderContext{
+		root:       w.root,
+		node:       node,
+		language:   language,
+		content:    contentLanguage(variation, language),
+		version:    version,
+		menus:      w.menus,
+		taxonomies: w.taxonomies,
+		www:        w.www,
+	}
+	temp := getTemplate(node, variation, ctx.funcs())
+	ctx.template = temp
+	renderNav(temp, data)
+	err = temp.Execute(f, data)
+	if err != nil {
+		fmt.Println("WARNING:", err.Error())
+	}
 
-	return nil
+	err = f.Close()
+	if err != nil {
+		panic(err.Error())
+	}
 }
 
 func getNode(root *Node, path string) *Node {
@@ -436,93 +496,66 @@ func getBestVariation(variations []*Variation, language, version string) *Variat
 	return variation
 }
 
+// isOwnLanguage returns true if variation is written in lang and not rendered
+// as a fallback, variations without language belong to the default language
+func isOwnLanguage(variation *Variation, lang string) bool {
+	return variation.Language == lang || (variation.Language == "" && lang == languages[0])
+}
+
 var basepath = "/"
 
+// baseurl is the public url of the site, used to build absolute links
+var baseurl = ""
+
 func getLink(n *Node, lang, version string) string {
 	variation := getBestVariation(n.Variations, lang, version)
-	return path.Join(basepath, getOutputPath(n, variation, lang, version))
-}
-
-func getBreadcrumb(n *Node, lang, version string) string {
-	breadcrumb := []*Node{}
-
-	for n != nil && len(n.Variations) > 0 {
-		if n.Parent == nil {
-			break
-		}
-		breadcrumb = append(breadcrumb, n)
-		n = n.Parent
+	if variation != nil && variation.FrontMatter.Link != "" {
+		return variation.FrontMatter.Link
 	}
-
-	if len(breadcrumb) < 2 {
+	if variation != nil && variation.FrontMatter.Separator {
 		return ""
 	}
-
-	slices.Reverse(breadcrumb)
-
-	result := ""
-
-	result += `<div class="breadcrumb">`
-	for i, node := range breadcrumb {
-		if node.Name == "{version}" {
-			continue
-		}
-		if i > 0 {
-			result += `<span class="arrow">→</span>`
-		}
-		v := getBestVariation(node.Variations, lang, version)
-		class := "item"
-		if i == len(breadcrumb)-1 {
-			class += " selected"
-		}
-		result += `<a class="` + class + `" href="` + getLink(node, lang, version) + `">` + v.Title + `</a>`
-	}
-	result += `</div>`
-
-	return result
+	return path.Join(basepath, getOutputPath(n, variation, lang, version))
 }
 
-func getIndex(root, target *Node, lang, version string) string {
-
-	nodesToParent := []*Node{}
-	n := target
-	for n != nil {
-		nodesToParent = append(nodesToParent, n)
-		n = n.Parent
+func getAbsoluteLink(n *Node, lang, version string) string {
+	link := getLink(n, lang, version)
+	if !strings.HasPrefix(link, "/") {
+		return link // external or no link at all
 	}
+	return strings.TrimSuffix(baseurl, "/") + link
+}
 
-	result := ""
-
-	for _, child := range root.Children {
-
-		if child.Name == "{version}" {
-			result += getIndex(child, target, lang, version)
-			continue
-		}
-
-		link := getLink(child, lang, version)
-
-		variation := getBestVariation(child.Variations, lang, version)
-
-		class := "item"
-		if nodeIn(nodesToParent, child) {
-			class += " active"
-		}
-		if child == target {
-			class += " selected"
-		}
+// Alternate is a translation of a page, see <link rel="alternate" hreflang>
+type Alternate struct {
+	Language string
+	Url      string
+}
 
-		result += `<div class="` + class + `"><a href="` + link + `">` + variation.Title + `</a></div>` + "\n"
+// getAlternates returns the languages that really have the node plus the
+// x-default entry pointing to the default language
+func getAlternates(n *Node, version string) []Alternate {
+	result := []Alternate{}
 
-		if len(child.Children) == 0 {
+	for _, l := range languages {
+		if !hasContent(n, l, "") { // in any version
 			continue
 		}
+		result = append(result, Alternate{
+			Language: l,
+			Url:      getAbsoluteLink(n, l, version),
+		})
+	}
 
-		result += `<div class="children">` + "\n"
-		result += getIndex(child, target, lang, version)
-		result += `</div>` + "\n"
+	if len(result) == 0 {
+		return result
 	}
 
+	result = append(result, Alternate{
+		Language: "x-default",
+		Url:      getAbsoluteLink(n, languages[0], version),
+	})
+
 	return result
 }
 
@@ -537,20 +570,30 @@ func traverseNodes(root *Node, callback func(*Node)) {
 
 type Node struct {
 	Order      int
+	Date       time.Time // from names like 2024-05-01_slug, see getDate
 	Name       string
 	Path       string
 	Children   []*Node
 	Variations []*Variation
 	Parent     *Node
-	Template   string
+	Template   string            // default template for the node and its descendants
+	Layouts    map[string]string // templates that can be chosen by name, indexed by name
+	Partials   map[string]string // templates included in every layout, indexed by name
+
+	drafts int // number of variations left out because they are not published
+
+	staticIndex bool // src/index.html is copied as is, see isAutoSection
 }
 
 type Variation struct {
-	Url      string
-	Language string
-	Version  string
-	Filename string
-	Title    string
+	Url         string
+	Language    string
+	Version     string
+	Filename    string
+	Title       string
+	Description string
+	FrontMatter *FrontMatter
+	Draft       bool // only included when building drafts
 }
 
 func (n *Node) PrettyPrint(indent int) {
@@ -563,7 +606,8 @@ func (n *Node) PrettyPrint(indent int) {
 func getOutputPath(node *Node, variation *Variation, lang, version string) string {
 
 	if variation == nil {
-		return "NIL VARIATION!!! panic(?)"
+		// sections without any content, see isAutoSection
+		variation = &Variation{Language: lang, Version: version}
 	}
 
 	result := []string{}
@@ -651,15 +695,80 @@ func copyFile(src, dst string) {
 	s.Close()
 }
 
+// joinWww returns the filename of an output path, paths that leave www are
+// rejected: `../../etc/passwd` or `/../x`
+func joinWww(www, p string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(p, "/"))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("output path '%s' is outside %s", p, www)
+	}
+	return path.Join(www, clean), nil
+}
+
+// writeRedirect writes an html page in `from` that redirects to `to`
+func writeRedirect(www, from, to string) {
+
+	filename, err := joinWww(www, from)
+	if err != nil {
+		fmt.Println("WARNING: alias:", err.Error())
+		return
+	}
+	if path.Ext(filename) == "" {
+		filename = path.Join(filename, "index.html")
+	}
+
+	err = os.MkdirAll(path.Dir(filename), 0777)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	to = html.EscapeString(to)
+	page := `<!DOCTYPE html>
+<html>
+<head>
+    <title>` + to + `</title>
+    <link rel="canonical" href="` + to + `">
+    <meta http-equiv="refresh" content="0; url=` + to + `">
+</head>
+</html>
+`
+
+	err = os.WriteFile(filename, []byte(page), 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
 func readNodes(root *Node, src, www string) { // todo: return errors instead of miserably panic
 	entries, err := os.ReadDir(src)
 	if err != nil {
 		panic(err.Error())
 	}
 
+	templates := []string{}
+
 	for _, entry := range entries {
+		if isThemePath(path.Join(src, entry.Name())) {
+			continue // read by loadTheme
+		}
 		if entry.IsDir() {
+			if root.Parent == nil && entry.Name() == "i18n" {
+				continue // catalogs are read by readCatalogs
+			}
+			if entry.Name() == "layouts" {
+				readTemplates(os.DirFS(src), entry.Name(), "", func(name, filename string) {
+					root.addLayout(name, path.Join(src, filename))
+				})
+				continue
+			}
+			if entry.Name() == "partials" {
+				readTemplates(os.DirFS(src), entry.Name(), "", func(name, filename string) {
+					root.addPartial(name, path.Join(src, filename))
+				})
+				continue
+			}
 			var order int
+			var date time.Time
 			var name string
 			if entry.Name() == "{version}" {
 				name = entry.Name()
@@ -671,28 +780,47 @@ func readNodes(root *Node, src, www string) { // todo: return errors instead of
 				}
 				order, err = strconv.Atoi(parts[0])
 				if err != nil {
-					continue
+					// posts are named by date: 2024-05-01_new-regions
+					date, err = time.ParseInLocation(time.DateOnly, parts[0], time.Local)
+					if err != nil {
+						continue
+					}
 				}
 				name = parts[1]
 			}
 
 			newNode := &Node{
-				Order: order,
-				Name:  name,
-				Path:  src,
+				Order:  order,
+				Date:   date,
+				Name:   name,
+				Path:   src,
+				Parent: root,
 			}
 			readNodes(newNode, path.Join(src, entry.Name()), www)
 
-			newNode.Parent = root
+			// all pages of the node are drafts and nothing under it is
+			// published, published children keep the node as a section
+			if newNode.drafts > 0 && len(newNode.Variations) == 0 && len(newNode.Children) == 0 {
+				continue
+			}
+
 			root.Children = append(root.Children, newNode)
 
 		} else {
 			ext := strings.ToLower(path.Ext(entry.Name()))
 			if ext == ".gohtml" {
-				root.Template = path.Join(src, entry.Name())
+				name := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+				root.addLayout(name, path.Join(src, entry.Name()))
+				if name == defaultLayout {
+					root.Template = path.Join(src, entry.Name())
+				}
+				templates = append(templates, path.Join(src, entry.Name()))
 				continue
 			}
-			if !in([]string{".html", ".md"}, ext) {
+			if root.Parent == nil && entry.Name() == siteFile {
+				continue // read by readSite
+			}
+			if !in([]string{".html", ".md", ".link"}, ext) {
 				copyFile(path.Join(src, entry.Name()), path.Join(www, entry.Name()))
 				continue
 			}
@@ -700,7 +828,10 @@ func readNodes(root *Node, src, www string) { // todo: return errors instead of
 			base := strings.ToLower(strings.TrimSuffix(path.Base(entry.Name()), path.Ext(entry.Name())))
 			parts := strings.Split(base, "_")
 
-			if len(parts) == 1 {
+			if len(parts) == 1 && ext != ".link" {
+				if root.Parent == nil && base == "index" && ext == ".html" {
+					root.staticIndex = true
+				}
 				copyFile(path.Join(src, entry.Name()), path.Join(www, entry.Name()))
 				continue
 			}
@@ -722,67 +853,145 @@ func readNodes(root *Node, src, www string) { // todo: return errors instead of
 
 			filename := path.Join(src, entry.Name())
 
-			title := getTitle(filename)
+			var frontMatter *FrontMatter
+			var title, description string
+			if ext == ".link" {
+				frontMatter = readDescriptor(filename)
+				frontMatter.Separator = frontMatter.Link == "" // without link it is a section header
+			} else {
+				var htmlReader io.Reader
+				frontMatter, htmlReader = readSource(filename)
+				title, description = getTitle(htmlReader)
+			}
+
+			// front matter takes precedence over name convention and content
+			if frontMatter.Title != "" {
+				title = frontMatter.Title
+			}
+			if frontMatter.Description != "" {
+				description = frontMatter.Description
+			}
+			if frontMatter.Slug != "" {
+				friendlyUrl = frontMatter.Slug
+			}
+			if frontMatter.Language != "" && !in(languages, frontMatter.Language) {
+				fmt.Printf("WARNING: %s: language '%s' is not configured\n", filename, frontMatter.Language)
+			} else if frontMatter.Language != "" {
+				lang = frontMatter.Language
+			}
+			if frontMatter.Version != "" {
+				version = frontMatter.Version
+			}
+			if frontMatter.Order != nil {
+				root.Order = *frontMatter.Order
+			}
+
+			published := frontMatter.isPublished(now)
+			if !published && !drafts {
+				fmt.Println("draft:", filename)
+				root.drafts++
+				continue
+			}
+
 			if title == "" {
 				title = friendlyUrl // fallback
 				base, _ := os.Getwd()
-				fmt.Printf("WARNING: %s:1 needs a title <h1>\n", path.Join(base, filename))
+				fmt.Printf("WARNING: %s:1 needs a title <h1> or front matter title\n", path.Join(base, filename))
 			}
 
 			root.Variations = append(root.Variations, &Variation{
-				Url:      friendlyUrl,
-				Language: lang,
-				Version:  version,
-				Filename: filename,
-				Title:    title,
+				Url:         friendlyUrl,
+				Language:    lang,
+				Version:     version,
+				Filename:    filename,
+				Title:       title,
+				Description: description,
+				FrontMatter: frontMatter,
+				Draft:       !published,
 			})
 
 		}
 
 	}
 
-	sort.Slice(root.Children, func(i, j int) bool {
-		return root.Children[i].Order < root.Children[j].Order
+	// the theme template is the default one until Src provides its own
+	hasDefault := root.Template != "" && !isThemeFile(root.Template)
+	if !hasDefault && len(templates) == 1 {
+		root.Template = templates[0] // a single template is the default one
+	}
+	if !hasDefault && len(templates) > 1 {
+		fmt.Printf("WARNING: %s has several templates but none is %s.gohtml, using parent template\n", src, defaultLayout)
+	}
+
+	// dated nodes (posts) go newest first
+	sort.SliceStable(root.Children, func(i, j int) bool {
+		a, b := root.Children[i], root.Children[j]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.Date.After(b.Date)
 	})
 
 }
 
-func getTitle(filename string) string {
+// getTitle returns the first <h1> and the first paragraph of a document
+func getTitle(htmlReader io.Reader) (title, description string) {
 
-	var htmlReader io.Reader
-	f, err := os.Open(filename)
+	doc, err := html.Parse(htmlReader)
 	if err != nil {
 		panic(err.Error())
 	}
-	defer f.Close()
 
-	switch strings.ToLower(path.Ext(filename)) {
-	case ".html":
+	traverseHtml(doc, func(node *html.Node) {
+		if node.Data == "h1" && node.FirstChild != nil {
+			title = node.FirstChild.Data
+		}
+	})
 
-		htmlReader = f
+	description = getDescription([]*html.Node{doc})
 
-	case ".md":
-		b, err := io.ReadAll(f)
-		if err != nil {
-			panic(err.Error())
-		}
+	return
+}
 
-		htmlReader = md2html(b)
+// getDescription returns the text of the first non empty paragraph, trimmed
+// to a length suitable for <meta name="description">
+func getDescription(nodes []*html.Node) string {
+
+	description := ""
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && n.Data == "p" {
+			description = strings.TrimSpace(textContent(n))
+		}
+		if description != "" {
+			break
+		}
+		traverseHtml(n, func(node *html.Node) {
+			if description == "" && node.Data == "p" {
+				description = strings.TrimSpace(textContent(node))
+			}
+		})
 	}
 
-	title := ""
-	doc, err := html.Parse(htmlReader)
-	if err != nil {
-		panic(err.Error())
+	description = strings.Join(strings.Fields(description), " ")
+
+	const maxLength = 160
+	if runes := []rune(description); len(runes) > maxLength {
+		description = strings.TrimSpace(string(runes[:maxLength-1])) + "…"
 	}
 
-	traverseHtml(doc, func(node *html.Node) {
-		if node.Data == "h1" && node.FirstChild != nil {
-			title = node.FirstChild.Data
-		}
-	})
+	return description
+}
 
-	return title
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	result := ""
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		result += textContent(c)
+	}
+	return result
 }
 
 func traverseHtml(n *html.Node, callback func(node *html.Node)) {
@@ -0,0 +1,227 @@
+package holadoc
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestJoinWww(t *testing.T) {
+
+	cases := []struct {
+		path string
+		want string // empty if rejected
+	}{
+		{"index.html", "www/index.html"},
+		{"/old/page", "www/old/page"},
+		{"docs/../blog/index.html", "www/blog/index.html"},
+		{"/", "www"},
+		{"..", ""},
+		{"../escape", ""},
+		{"/../escape", ""},
+		{"docs/../../escape", ""},
+		{"../../../etc/passwd", ""},
+	}
+
+	for _, c := range cases {
+		got, err := joinWww("www", c.path)
+		if c.want == "" && err == nil {
+			t.Errorf("joinWww(%q) = %q, should be rejected", c.path, got)
+		}
+		if c.want != "" && (err != nil || got != c.want) {
+			t.Errorf("joinWww(%q) = %q, %v, want %q", c.path, got, err, c.want)
+		}
+	}
+}
+
+// writeFiles creates the files of a source tree, by path
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filename := path.Join(dir, name)
+		err := os.MkdirAll(path.Dir(filename), 0777)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filename, []byte(content), 0666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadNodesSkipsTheme(t *testing.T) {
+
+	defer func(l, v []string, p string) { languages, versions, themePath = l, v, p }(languages, versions, themePath)
+	languages = []string{"en"}
+	versions = []string{""}
+
+	src, www := t.TempDir(), t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"10_docs/docs_en.md":           "# Docs\n",
+		"img/logo.png":                 "png",
+		"mytheme/theme.json":           `{"name": "mytheme"}`,
+		"mytheme/layouts/base.gohtml":  "{{ .content }}",
+		"mytheme/partials/menu.gohtml": "",
+		"other.zip":                    "zip",
+	})
+
+	cases := []struct {
+		theme string
+		want  []string // in www
+		skip  []string // not in www
+	}{
+		{"", []string{"img/logo.png", "mytheme/theme.json", "other.zip"}, nil},
+		{"mytheme", []string{"img/logo.png", "other.zip"}, []string{"mytheme"}},
+		{"other.zip", []string{"img/logo.png", "mytheme/theme.json"}, []string{"other.zip"}},
+	}
+
+	for _, c := range cases {
+
+		os.RemoveAll(www)
+		themePath = ""
+		if c.theme != "" {
+			themePath, _ = filepath.Abs(path.Join(src, c.theme))
+		}
+
+		readNodes(&Node{}, src, www)
+
+		for _, name := range c.want {
+			if _, err := os.Stat(path.Join(www, name)); err != nil {
+				t.Errorf("theme %q: %s is not copied", c.theme, name)
+			}
+		}
+		for _, name := range c.skip {
+			if _, err := os.Stat(path.Join(www, name)); err == nil {
+				t.Errorf("theme %q: %s is copied as content", c.theme, name)
+			}
+		}
+	}
+}
+
+func TestReadNodesDrafts(t *testing.T) {
+
+	defer func(l, v []string, d bool, n time.Time) { languages, versions, drafts, now = l, v, d, n }(languages, versions, drafts, now)
+	languages = []string{"en"}
+	versions = []string{""}
+	drafts = false
+	now = time.Now()
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"10_docs/docs_en.md":           "---\ndraft: true\n---\n# Docs\n",
+		"10_docs/10_intro/intro_en.md": "# Intro\n",
+		"10_docs/20_draft/draft_en.md": "---\ndraft: true\n---\n# Draft\n",
+		"20_old/old_en.md":             "---\ndraft: true\n---\n# Old\n",
+		"20_old/10_older/older_en.md":  "---\ndraft: true\n---\n# Older\n",
+		"30_blog/blog_en.md":           "# Blog\n",
+		"30_blog/10_post/post_en.md":   "---\npublishDate: 2999-01-01\n---\n# Post\n",
+		"30_blog/20_hello/hello_en.md": "# Hello\n",
+	})
+
+	root := &Node{}
+	readNodes(root, src, t.TempDir())
+
+	cases := []struct {
+		path     string
+		exists   bool
+		contents int // variations
+	}{
+		{"docs", true, 0},
+		{"docs/intro", true, 1},
+		{"docs/draft", false, 0},
+		{"old", false, 0},
+		{"old/older", false, 0},
+		{"blog", true, 1},
+		{"blog/post", false, 0},
+		{"blog/hello", true, 1},
+	}
+
+	for _, c := range cases {
+		n := getNode(root, c.path)
+		if (n != nil) != c.exists {
+			t.Errorf("%s exists = %v, want %v", c.path, n != nil, c.exists)
+			continue
+		}
+		if n != nil && len(n.Variations) != c.contents {
+			t.Errorf("%s has %d variations, want %d", c.path, len(n.Variations), c.contents)
+		}
+	}
+
+	// the section without its own page lists the published children
+	if docs := getNode(root, "docs"); docs != nil && !isAutoSection(docs, "en", "") {
+		t.Errorf("docs is not a generated section")
+	}
+}
+
+func TestGetAlternates(t *testing.T) {
+
+	defer func(l, v []string, u string) { languages, versions, baseurl = l, v, u }(languages, versions, baseurl)
+	languages = []string{"en", "es", "zh"}
+	versions = []string{""}
+	baseurl = "https://hola.cloud"
+
+	root := &Node{}
+	newNode := func(name string, variations ...*Variation) *Node {
+		n := &Node{Name: name, Parent: root, Variations: variations}
+		root.Children = append(root.Children, n)
+		return n
+	}
+	variation := func(lang string) *Variation {
+		return &Variation{Url: "install", Language: lang, Filename: "install_" + lang + ".md", FrontMatter: &FrontMatter{}}
+	}
+
+	cases := []struct {
+		node *Node
+		want []Alternate
+	}{
+		{newNode("install", variation("en"), variation("es")), []Alternate{
+			{"en", "https://hola.cloud/install/index.html"},
+			{"es", "https://hola.cloud/es/install/index.html"},
+			{"x-default", "https://hola.cloud/install/index.html"},
+		}},
+		{newNode("install", variation("es")), []Alternate{
+			{"es", "https://hola.cloud/es/install/index.html"},
+			{"x-default", "https://hola.cloud/install/index.html"},
+		}},
+		{newNode("empty"), []Alternate{}},
+	}
+
+	for _, c := range cases {
+		if got := getAlternates(c.node, ""); !slices.Equal(got, c.want) {
+			t.Errorf("getAlternates(%s) = %v, want %v", c.node.Name, got, c.want)
+		}
+	}
+}
+
+func TestGetDescription(t *testing.T) {
+
+	long := strings.Repeat("word ", 40)
+
+	cases := []struct {
+		html string
+		want string
+	}{
+		{"<h1>Title</h1><p>First  paragraph\n here</p><p>Second</p>", "First paragraph here"},
+		{"<h1>Title</h1><p> </p><p>Second</p>", "Second"},
+		{"<div><p>Nested <b>bold</b></p></div>", "Nested bold"},
+		{"<h1>Title</h1><pre>code</pre>", ""},
+		{"<p>" + long + "</p>", strings.TrimSpace(long[:159]) + "…"},
+	}
+
+	for _, c := range cases {
+		nodes, err := html.ParseFragment(strings.NewReader(c.html), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := getDescription(nodes); got != c.want {
+			t.Errorf("getDescription(%q) = %q, want %q", c.html, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package holadoc
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Catalog contains the UI messages for one language, indexed by key
+type Catalog map[string]string
+
+// catalogs are indexed by language, they are read from `i18n/{lang}.json`
+var catalogs = map[string]Catalog{}
+
+// default messages used when no catalog defines a key
+var defaultMessages = Catalog{
+	"footer":           "HolaDoc",
+	"onThisPage":       "On this page",
+	"breadcrumb.arrow": "→",
+	"draft":            "DRAFT",
+	"previous":         "Previous",
+	"next":             "Next",
+	"home":             "Home",
+	"related":          "See also",
+	"blog.more":        "Read more",
+	"blog.empty":       "No posts yet.",
+	"blog.newer":       "Newer posts",
+	"blog.older":       "Older posts",
+	"blog.archives":    "Archives",
+	"blog.authors":     "Authors",
+
+	"taxonomy.tags":       "Tags",
+	"taxonomy.categories": "Categories",
+}
+
+// human readable names for well known language codes, catalogs can override
+// them with the key `language.name`
+var languageNames = map[string]string{
+	"ar": "العربية",
+	"de": "Deutsch",
+	"en": "English",
+	"es": "Español",
+	"fr": "Français",
+	"he": "עברית",
+	"it": "Italiano",
+	"ja": "日本語",
+	"ko": "한국어",
+	"pt": "Português",
+	"ru": "Русский",
+	"zh": "中文",
+}
+
+// languages written from right to left, catalogs can override the direction
+// with the key `language.dir`
+var rtlLanguages = []string{"ar", "dv", "fa", "he", "ps", "ur", "yi"}
+
+// readCatalogs reads the catalogs in dir, keys already read from other
+// catalogs (the theme) are overridden. Themes ship catalogs for languages a
+// site may not use, so only site catalogs are checked against languages.
+func readCatalogs(fsys fs.FS, dir string, site bool) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.ToLower(path.Ext(entry.Name())) != ".json" {
+			continue
+		}
+
+		filename := path.Join(dir, entry.Name())
+		lang := strings.ToLower(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+		if site && !in(languages, lang) {
+			fmt.Printf("WARNING: %s: language '%s' is not configured\n", filename, lang)
+		}
+
+		b, err := fs.ReadFile(fsys, filename)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		catalog := Catalog{}
+		err = json.Unmarshal(b, &catalog)
+		if err != nil {
+			panic(filename + ": " + err.Error())
+		}
+
+		if catalogs[lang] == nil {
+			catalogs[lang] = Catalog{}
+		}
+		for key, message := range catalog {
+			catalogs[lang][key] = message
+		}
+	}
+}
+
+// translate returns the message for a key following the same fallback chain
+// as content: requested language, default language, any language and finally
+// the built-in default or the key itself
+func translate(lang, key string) string {
+
+	if message, ok := catalogs[lang][key]; ok {
+		return message
+	}
+
+	// fallback by language
+	for _, l := range languages {
+		if message, ok := catalogs[l][key]; ok {
+			return message
+		}
+	}
+
+	if message, ok := defaultMessages[key]; ok {
+		return message
+	}
+
+	return key
+}
+
+// languageName returns the name of a language in the language itself
+func languageName(lang string) string {
+
+	if name, ok := catalogs[lang]["language.name"]; ok {
+		return name
+	}
+
+	if name, ok := languageNames[lang]; ok {
+		return name
+	}
+
+	return lang
+}
+
+// getDirection returns the writing direction of a language: "ltr" or "rtl"
+func getDirection(lang string) string {
+
+	if dir, ok := catalogs[lang]["language.dir"]; ok {
+		return dir
+	}
+
+	if in(rtlLanguages, lang) {
+		return "rtl"
+	}
+
+	return "ltr"
+}
+
+// getArrow returns the breadcrumb arrow for a language, arrows in catalogs are
+// written for left to right languages. lang must be the language of the
+// content, the one that decides the direction of the page.
+func getArrow(lang string) string {
+	arrow := translate(lang, "breadcrumb.arrow")
+	if getDirection(lang) == "rtl" {
+		arrow = flipArrow(arrow)
+	}
+	return arrow
+}
+
+// flipArrow mirrors the arrows written for left to right languages. Only
+// arrows without the Unicode Bidi_Mirrored property, browsers already mirror
+// the rest (< > « ») in right to left text.
+func flipArrow(arrow string) string {
+	return strings.NewReplacer(
+		"→", "←",
+		"←", "→",
+	).Replace(arrow)
+}
+
+// contentLanguage returns the language a page is written in: the language of
+// the variation, or lang for variations valid for any language. Fallback
+// pages are written in another language than the requested one.
+func contentLanguage(variation *Variation, lang string) string {
+	return cmp.Or(variation.Language, lang)
+}
@@ -0,0 +1,137 @@
+package holadoc
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestFlipArrow(t *testing.T) {
+
+	cases := []struct {
+		arrow string
+		want  string
+	}{
+		{"→", "←"},
+		{"←", "→"},
+		{" → ", " ← "},
+		{">", ">"}, // mirrored by the browser
+		{"»", "»"},
+		{"<", "<"},
+		{"/", "/"},
+	}
+
+	for _, c := range cases {
+		if got := flipArrow(c.arrow); got != c.want {
+			t.Errorf("flipArrow(%q) = %q, want %q", c.arrow, got, c.want)
+		}
+	}
+}
+
+func TestDirectionAndArrow(t *testing.T) {
+
+	defer func(c map[string]Catalog) { catalogs = c }(catalogs)
+	catalogs = map[string]Catalog{
+		"en": {"breadcrumb.arrow": "→"},
+		"ar": {"breadcrumb.arrow": "→"},
+		"fa": {"breadcrumb.arrow": "»"},
+		"xx": {"breadcrumb.arrow": "→", "language.dir": "rtl"},
+	}
+
+	cases := []struct {
+		variation string // language of the content
+		lang      string // requested language
+		dir       string
+		arrow     string
+	}{
+		{"en", "en", "ltr", "→"},
+		{"ar", "ar", "rtl", "←"},
+		{"fa", "fa", "rtl", "»"},
+		{"xx", "xx", "rtl", "←"},
+		{"en", "ar", "ltr", "→"}, // fallback, written in english
+		{"", "ar", "rtl", "←"},   // valid for any language
+	}
+
+	for _, c := range cases {
+		lang := contentLanguage(&Variation{Language: c.variation}, c.lang)
+		if dir := getDirection(lang); dir != c.dir {
+			t.Errorf("%q for %q: dir = %q, want %q", c.variation, c.lang, dir, c.dir)
+		}
+		if arrow := getArrow(lang); arrow != c.arrow {
+			t.Errorf("%q for %q: arrow = %q, want %q", c.variation, c.lang, arrow, c.arrow)
+		}
+	}
+}
+
+func TestReadCatalogs(t *testing.T) {
+
+	defer func(c map[string]Catalog) { catalogs = c }(catalogs)
+	catalogs = map[string]Catalog{}
+
+	theme := fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{"footer": "Theme", "next": "Next page"}`)},
+		"i18n/es.json": {Data: []byte(`{"footer": "Tema"}`)},
+	}
+	site := fstest.MapFS{
+		"i18n/EN.json":      {Data: []byte(`{"footer": "Site"}`)},
+		"i18n/notes.txt":    {Data: []byte(`not a catalog`)},
+		"i18n/other/x.json": {Data: []byte(`{"footer": "Nested"}`)},
+	}
+
+	readCatalogs(theme, "i18n", false)
+	readCatalogs(site, "i18n", false)
+	readCatalogs(site, "missing", false)
+
+	cases := []struct {
+		lang, key string
+		want      string
+	}{
+		{"en", "footer", "Site"},    // the site overrides the theme
+		{"en", "next", "Next page"}, // key by key
+		{"es", "footer", "Tema"},
+	}
+
+	for _, c := range cases {
+		if got := catalogs[c.lang][c.key]; got != c.want {
+			t.Errorf("catalog %s[%q] = %q, want %q", c.lang, c.key, got, c.want)
+		}
+	}
+	if len(catalogs) != 2 {
+		t.Errorf("read %d catalogs, want 2", len(catalogs))
+	}
+}
+
+func TestTranslate(t *testing.T) {
+
+	defer func(c map[string]Catalog, l []string) { catalogs, languages = c, l }(catalogs, languages)
+	languages = []string{"en", "es", "zh"}
+	catalogs = map[string]Catalog{
+		"en": {"footer": "Made with love", "language.name": "English (US)"},
+		"es": {"footer": "Hecho con cariño", "onThisPage": "En esta página"},
+		"zh": {},
+	}
+
+	cases := []struct {
+		lang, key string
+		want      string
+	}{
+		{"es", "footer", "Hecho con cariño"},
+		{"zh", "footer", "Made with love"},     // default language
+		{"zh", "onThisPage", "En esta página"}, // any language
+		{"zh", "next", "Next"},                 // built-in default
+		{"zh", "unknown.key", "unknown.key"},
+		{"fr", "footer", "Made with love"}, // no catalog
+	}
+
+	for _, c := range cases {
+		if got := translate(c.lang, c.key); got != c.want {
+			t.Errorf("translate(%q, %q) = %q, want %q", c.lang, c.key, got, c.want)
+		}
+	}
+
+	names := map[string]string{"en": "English (US)", "es": "Español", "xx": "xx"}
+	for lang, want := range names {
+		if got := languageName(lang); got != want {
+			t.Errorf("languageName(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package holadoc
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template/parse"
+)
+
+// name of the default template of a directory
+const defaultLayout = "template"
+
+// name of the layout other layouts can extend with {{ define "block" }}
+const baseLayout = "base"
+
+func (n *Node) addLayout(name, filename string) {
+	if n.Layouts == nil {
+		n.Layouts = map[string]string{}
+	}
+	if previous, exists := n.Layouts[name]; exists && !isThemeFile(previous) {
+		fmt.Printf("WARNING: %s overrides layout '%s' from %s\n", filename, name, previous)
+	}
+	n.Layouts[name] = filename
+}
+
+func (n *Node) addPartial(name, filename string) {
+	if n.Partials == nil {
+		n.Partials = map[string]string{}
+	}
+	n.Partials[name] = filename
+}
+
+// readTemplates reads all .gohtml files in dir, names are relative paths
+// without extension: `partials/cards/grid.gohtml` is `cards/grid`
+func readTemplates(fsys fs.FS, dir, prefix string, add func(name, filename string)) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for _, entry := range entries {
+		filename := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			readTemplates(fsys, filename, path.Join(prefix, entry.Name()), add)
+			continue
+		}
+		if strings.ToLower(path.Ext(entry.Name())) != ".gohtml" {
+			continue
+		}
+		add(path.Join(prefix, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))), filename)
+	}
+}
+
+// findLayout looks for a layout by name from node up to the root
+func findLayout(node *Node, name string) string {
+	for n := node; n != nil; n = n.Parent {
+		if filename, ok := n.Layouts[name]; ok {
+			return filename
+		}
+	}
+	return ""
+}
+
+// findTemplate returns the nearest default template from node up to the root
+func findTemplate(node *Node) string {
+	for n := node; n != nil; n = n.Parent {
+		if n.Template != "" {
+			return n.Template
+		}
+	}
+	return ""
+}
+
+// getTemplate returns the template for a variation of a node. The template is
+// chosen by front matter (`template` file or `layout` name) or it is the
+// nearest default template. Partials and the base layout are included.
+func getTemplate(node *Node, variation *Variation, funcs template.FuncMap) *template.Template {
+
+	filename := ""
+
+	switch {
+	case variation.FrontMatter.Template != "":
+		// template chosen by front matter, relative to the source file
+		filename = path.Join(path.Dir(variation.Filename), variation.FrontMatter.Template)
+	case variation.FrontMatter.Layout != "":
+		filename = findLayout(node, variation.FrontMatter.Layout)
+		if filename == "" {
+			fmt.Printf("WARNING: %s: layout '%s' does not exist\n", variation.Filename, variation.FrontMatter.Layout)
+		}
+	}
+
+	if filename == "" {
+		filename = findTemplate(node)
+	}
+
+	if filename == "" {
+		panic("No template found!!!")
+	}
+
+	return parseTemplate(node, filename, funcs)
+}
+
+// parseTemplate parses a template file together with the default partials,
+// the partials found from node up to the root and the base layout. If the
+// template only defines blocks, the base layout is the one executed.
+func parseTemplate(node *Node, filename string, funcs template.FuncMap) *template.Template {
+
+	temp := template.New(filename).Funcs(funcs)
+
+	_, err := temp.New("partials").Parse(partials)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	// partials closer to the node take precedence
+	ancestors := []*Node{}
+	for n := node; n != nil; n = n.Parent {
+		ancestors = append([]*Node{n}, ancestors...)
+	}
+	for _, n := range ancestors {
+		for name, partial := range n.Partials {
+			parseFile(temp.New(name), partial)
+		}
+	}
+
+	base := findLayout(node, baseLayout)
+	if base != "" && base != filename {
+		parseFile(temp.New(base), base)
+	}
+
+	parseFile(temp, filename)
+
+	if base != "" && base != filename && isEmptyTemplate(temp.Tree) {
+		return temp.Lookup(base)
+	}
+
+	return temp
+}
+
+func parseFile(temp *template.Template, filename string) {
+
+	gohtml, err := readFile(filename)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	_, err = temp.Parse(string(gohtml))
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+// isEmptyTemplate returns true if a template has nothing but blanks outside
+// its {{ define }} blocks
+func isEmptyTemplate(tree *parse.Tree) bool {
+	if tree == nil || tree.Root == nil {
+		return true
+	}
+	for _, n := range tree.Root.Nodes {
+		text, ok := n.(*parse.TextNode)
+		if !ok || strings.TrimSpace(string(text.Text)) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package holadoc
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestGetTemplate(t *testing.T) {
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"template.gohtml":             `root {{ template "header" }}`,
+		"base.gohtml":                 `base[{{ block "main" . }}default{{ end }}]`,
+		"layouts/wide.gohtml":         `{{ define "main" }}wide {{ template "header" }}{{ end }}`,
+		"layouts/full.gohtml":         `full`,
+		"partials/header.gohtml":      `header`,
+		"docs/template.gohtml":        `docs {{ template "header" }}`,
+		"docs/partials/header.gohtml": `docs header`,
+		"docs/custom.gohtml":          `custom`,
+	})
+
+	root := &Node{Template: path.Join(src, "template.gohtml")}
+	root.addLayout(baseLayout, path.Join(src, "base.gohtml"))
+	root.addLayout("wide", path.Join(src, "layouts/wide.gohtml"))
+	root.addLayout("full", path.Join(src, "layouts/full.gohtml"))
+	root.addPartial("header", path.Join(src, "partials/header.gohtml"))
+	docs := &Node{Name: "docs", Parent: root, Template: path.Join(src, "docs/template.gohtml")}
+	docs.addPartial("header", path.Join(src, "docs/partials/header.gohtml"))
+	page := &Node{Name: "page", Parent: docs}
+	blog := &Node{Name: "blog", Parent: root}
+
+	cases := []struct {
+		name        string
+		node        *Node
+		frontMatter *FrontMatter
+		want        string
+	}{
+		{"root template", blog, &FrontMatter{}, "root header"},
+		{"nearest template", page, &FrontMatter{}, "docs docs header"}, // nearest partial too
+		{"layout with blocks", blog, &FrontMatter{Layout: "wide"}, "base[wide header]"},
+		{"layout from a child", page, &FrontMatter{Layout: "wide"}, "base[wide docs header]"},
+		{"full layout", blog, &FrontMatter{Layout: "full"}, "full"},
+		{"missing layout", blog, &FrontMatter{Layout: "missing"}, "root header"},
+		{"template file", page, &FrontMatter{Template: "custom.gohtml"}, "custom"},
+	}
+
+	for _, c := range cases {
+		variation := &Variation{Filename: path.Join(src, "docs/page_en.md"), FrontMatter: c.frontMatter}
+		temp := getTemplate(c.node, variation, (&renderContext{root: root, node: c.node}).funcs())
+
+		b := &strings.Builder{}
+		err := temp.Execute(b, nil)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if b.String() != c.want {
+			t.Errorf("%s: %q, want %q", c.name, b.String(), c.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package holadoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// MenuEntry is an entry of a named menu, declared in site.json or by front
+// matter (`menu: main` and `weight: 20`)
+type MenuEntry struct {
+	Path     string       `json:"path"`  // node path
+	Url      string       `json:"url"`   // external url, when there is no path
+	Label    Labels       `json:"label"` // defaults to the page title
+	Weight   int          `json:"weight"`
+	Children []*MenuEntry `json:"children"`
+
+	node *Node
+}
+
+// Labels are indexed by language, a plain string is valid for any language
+type Labels map[string]string
+
+func (l *Labels) UnmarshalJSON(b []byte) error {
+	s := ""
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l = Labels{"": s}
+		return nil
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*l = m
+	return nil
+}
+
+// get follows the same fallback chain as content
+func (l Labels) get(lang string) string {
+	if label, ok := l[lang]; ok {
+		return label
+	}
+	if label, ok := l[""]; ok {
+		return label
+	}
+	for _, language := range languages {
+		if label, ok := l[language]; ok {
+			return label
+		}
+	}
+	return ""
+}
+
+// getMenus merges the menus from site.json with the ones declared by front
+// matter and resolves their paths
+func getMenus(root *Node, site map[string][]*MenuEntry) map[string][]*MenuEntry {
+
+	menus := map[string][]*MenuEntry{}
+
+	var resolve func(entries []*MenuEntry, menu string) []*MenuEntry
+	resolve = func(entries []*MenuEntry, menu string) []*MenuEntry {
+		result := []*MenuEntry{}
+		for _, entry := range entries {
+			if entry.Path != "" || entry.Url == "" {
+				entry.node = getNode(root, entry.Path)
+				if entry.node == nil {
+					fmt.Printf("WARNING: %s: menu '%s': path '%s' does not exist\n", siteFile, menu, entry.Path)
+					continue
+				}
+			}
+			entry.Children = resolve(entry.Children, menu)
+			result = append(result, entry)
+		}
+		return result
+	}
+
+	for name, entries := range site {
+		menus[name] = resolve(entries, name)
+	}
+
+	traverseNodes(root, func(node *Node) {
+		declared := map[string]bool{}
+		for _, variation := range node.Variations {
+			for _, name := range variation.FrontMatter.Menus {
+				if declared[name] {
+					continue
+				}
+				declared[name] = true
+				menus[name] = append(menus[name], &MenuEntry{
+					Path:   getNodePath(node),
+					Weight: variation.FrontMatter.Weight,
+					node:   node,
+				})
+			}
+		}
+	})
+
+	for _, entries := range menus {
+		sortMenu(entries)
+	}
+
+	return menus
+}
+
+func sortMenu(entries []*MenuEntry) {
+	slices.SortStableFunc(entries, func(a, b *MenuEntry) int {
+		return a.Weight - b.Weight
+	})
+	for _, entry := range entries {
+		sortMenu(entry.Children)
+	}
+}
+
+// getMenu returns a menu seen from the node being rendered
+func getMenu(entries []*MenuEntry, current *Node, lang, version string) []*NavItem {
+	return getMenuDepth(entries, current, lang, version, 0)
+}
+
+func getMenuDepth(entries []*MenuEntry, current *Node, lang, version string, depth int) []*NavItem {
+
+	result := []*NavItem{}
+
+	for _, entry := range entries {
+
+		var item *NavItem
+		if entry.node != nil {
+			item = newNavItem(entry.node, lang, version)
+			item.Active = isUnder(current, entry.node)
+			item.Selected = entry.node == current
+		} else {
+			item = &NavItem{
+				Url:      entry.Url,
+				Language: lang,
+				Version:  version,
+				Exists:   true,
+				External: true,
+			}
+		}
+
+		if label := entry.Label.get(lang); label != "" {
+			item.Title = label
+		}
+
+		item.Depth = depth
+		item.Children = getMenuDepth(entry.Children, current, lang, version, depth+1)
+		for _, child := range item.Children {
+			item.Active = item.Active || child.Active
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package holadoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestGetMenus(t *testing.T) {
+
+	defer func(l, v []string) { languages, versions = l, v }(languages, versions)
+	languages = []string{"en", "es"}
+	versions = []string{""}
+
+	variation := func(title string, frontMatter *FrontMatter) *Variation {
+		return &Variation{Title: title, Language: "en", FrontMatter: frontMatter}
+	}
+	root := &Node{}
+	docs := &Node{Name: "docs", Parent: root, Variations: []*Variation{variation("Docs", &FrontMatter{})}}
+	blog := &Node{Name: "blog", Parent: root, Variations: []*Variation{variation("Blog", &FrontMatter{})}}
+	post := &Node{Name: "post", Parent: blog, Variations: []*Variation{variation("Post", &FrontMatter{})}}
+	pricing := &Node{Name: "pricing", Parent: root, Variations: []*Variation{
+		variation("Pricing", &FrontMatter{Menus: []string{"main", "footer"}, Weight: 15}),
+		{Title: "Precios", Language: "es", FrontMatter: &FrontMatter{Menus: []string{"main"}, Weight: 15}},
+	}}
+	root.Children = []*Node{docs, blog, pricing}
+	blog.Children = []*Node{post}
+
+	site := &Site{}
+	err := json.Unmarshal([]byte(`{"menus": {"main": [
+		{"url": "https://github.com/fulldump/holadoc", "label": {"en": "Source", "es": "Código"}, "weight": 30},
+		{"path": "blog", "weight": 20, "children": [{"path": "blog/post"}, {"path": "blog/missing"}]},
+		{"path": "docs", "weight": 10, "label": "Documentation"},
+		{"path": "missing"}
+	]}}`), site)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menus := getMenus(root, site.Menus)
+
+	cases := []struct {
+		menu string
+		lang string
+		want []string // title url active external
+	}{
+		{"main", "en", []string{
+			"Documentation /docs/index.html false false",
+			"Pricing /pricing/index.html false false", // front matter, declared once
+			"Blog /blog/index.html true false",
+			"Source https://github.com/fulldump/holadoc false true",
+		}},
+		{"main", "es", []string{
+			"Documentation /es/docs/index.html false false",
+			"Precios /es/pricing/index.html false false",
+			"Blog /es/blog/index.html true false",
+			"Código https://github.com/fulldump/holadoc false true",
+		}},
+		{"footer", "en", []string{"Pricing /pricing/index.html false false"}},
+		{"unknown", "en", []string{}},
+	}
+
+	for _, c := range cases {
+		items := getMenu(menus[c.menu], post, c.lang, "")
+		if len(items) != len(c.want) {
+			t.Errorf("menu %s %s has %d items, want %d", c.menu, c.lang, len(items), len(c.want))
+			continue
+		}
+		for i, item := range items {
+			got := fmt.Sprintf("%s %s %v %v", item.Title, item.Url, item.Active, item.External)
+			if got != c.want[i] {
+				t.Errorf("menu %s %s [%d] = %q, want %q", c.menu, c.lang, i, got, c.want[i])
+			}
+		}
+	}
+
+	// children keep their order and resolve their paths
+	blogItem := getMenu(menus["main"], post, "en", "")[2]
+	if len(blogItem.Children) != 1 || !blogItem.Children[0].Selected || blogItem.Children[0].Depth != 1 {
+		t.Errorf("blog children = %+v, want the selected post", blogItem.Children)
+	}
+}
@@ -0,0 +1,305 @@
+package holadoc
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NavItem is an entry of a navigation structure: tree, breadcrumb, language
+// menu or version menu. Templates render them with `range`, see partials.
+type NavItem struct {
+	Title     string
+	Url       string
+	Language  string
+	Version   string
+	Active    bool // the current page is this item or one of its descendants
+	Selected  bool // the current page
+	Exists    bool // has its own content for Language and Version (no fallback)
+	External  bool // links outside the site
+	Separator bool // section header without link
+	Depth     int
+	Children  []*NavItem
+	Node      *Node `json:"-"`
+
+	// HasChildren is also true when Children are left out by TreeOptions
+	HasChildren bool
+}
+
+// partials render navigation data with the classic holadoc markup. Templates
+// can redefine any of them with {{ define "tree" }}...{{ end }}
+const partials = `
+{{- define "tree" -}}
+{{- range . -}}
+{{- if .Separator -}}
+<div class="item separator">{{ .Title }}</div>
+{{- else -}}
+<div class="item{{ if .Active }} active{{ end }}{{ if .Selected }} selected{{ end }}{{ if .External }} external{{ end }}{{ if .HasChildren }} has-children{{ end }}"><a href="{{ .Url }}"
+{{- if .External }} target="_blank" rel="noopener"{{ end }}>{{ .Title }}</a></div>
+{{- end }}
+{{ if .Children -}}
+<div class="children">
+{{ template "tree" .Children }}</div>
+{{ end -}}
+{{- end -}}
+{{- end -}}
+
+{{- define "breadcrumb" -}}
+{{- if . -}}
+<div class="breadcrumb">
+{{- range $i, $item := . -}}
+{{- if $i }}<span class="arrow">{{ arrow }}</span>{{ end -}}
+<a class="item{{ if .Selected }} selected{{ end }}" href="{{ .Url }}"><bdi>{{ .Title }}</bdi></a>
+{{- end -}}
+</div>
+{{- end -}}
+{{- end -}}
+
+{{- define "langMenu" -}}
+<div class="languages">
+{{- range . -}}
+<a class="{{ if .Selected }}selected{{ end }}" href="{{ .Url }}" hreflang="{{ .Language }}">{{ .Title }}</a>
+{{- end -}}
+</div>
+{{- end -}}
+
+{{- define "menu" -}}
+{{- range . -}}
+{{- if .Separator -}}
+<span class="separator">{{ .Title }}</span>
+{{ else -}}
+<a class="link{{ if .Active }} selected{{ end }}{{ if .External }} external{{ end }}" href="{{ .Url }}"
+{{- if .External }} target="_blank" rel="noopener"{{ end }}>{{ .Title }}</a>
+{{ end -}}
+{{- end -}}
+{{- end -}}
+
+{{- define "terms" -}}
+{{- if . -}}
+<div class="terms">
+{{- range . -}}
+<a class="term" href="{{ .Url }}">{{ .Name }}</a>
+{{- end -}}
+</div>
+{{- end -}}
+{{- end -}}
+
+{{- define "versionMenu" -}}
+{{- if . -}}
+<div class="versions">
+{{- range . -}}
+<a class="{{ if .Selected }}selected{{ end }}" href="{{ .Url }}">{{ .Title }}</a>
+{{- end -}}
+</div>
+{{- end -}}
+{{- end -}}
+`
+
+// navKeys are the template keys with the navigation rendered by its partial
+// (`{{ .tree }}`), the data is under the same key plus Items (`.treeItems`)
+var navKeys = []string{"tree", "breadcrumb", "langMenu", "versionMenu"}
+
+// renderNav adds the navigation html to data, rendered with the partials of
+// temp so templates can redefine the markup
+func renderNav(temp *template.Template, data map[string]any) {
+	for _, key := range navKeys {
+		if _, exists := data[key]; exists {
+			continue
+		}
+		b := &bytes.Buffer{}
+		err := temp.ExecuteTemplate(b, key, data[key+"Items"])
+		if err != nil {
+			fmt.Println("WARNING:", err.Error())
+		}
+		data[key] = template.HTML(b.String())
+	}
+}
+
+// hasContent returns true if the node has its own variation for lang and
+// version, variations without language or version are valid for any. An empty
+// version matches any version.
+func hasContent(n *Node, lang, version string) bool {
+	for _, v := range n.Variations {
+		if (v.Language == lang || v.Language == "") && (version == "" || v.Version == version || v.Version == "") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContentUpTo is like hasContent but a variation is still valid for later
+// versions, pages not changed since v1 are part of the v2 reading order
+func hasContentUpTo(n *Node, lang, version string) bool {
+	for _, v := range n.Variations {
+		if (v.Language == lang || v.Language == "") && (v.Version == "" || compareVersions(v.Version, version) <= 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVersions compares versions like v1, v1.2, v1.10.3 number by number
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(strings.ToLower(a), "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(strings.ToLower(b), "v"), ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xi, errx := strconv.Atoi(x)
+		yi, erry := strconv.Atoi(y)
+		if errx != nil || erry != nil {
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+			continue
+		}
+		if c := cmp.Compare(xi, yi); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// isHidden returns true for pages that are reachable but not listed
+func isHidden(n *Node, lang, version string) bool {
+	variation := getBestVariation(n.Variations, lang, version)
+	return variation != nil && variation.FrontMatter.Hidden
+}
+
+// isPage returns false for external links and separators, nodes that are
+// only entries of the tree
+func isPage(n *Node, lang, version string) bool {
+	variation := getBestVariation(n.Variations, lang, version)
+	return variation == nil || (variation.FrontMatter.Link == "" && !variation.FrontMatter.Separator)
+}
+
+func newNavItem(n *Node, lang, version string) *NavItem {
+	item := &NavItem{
+		Title:    n.Name,
+		Url:      getLink(n, lang, version),
+		Language: lang,
+		Version:  version,
+		Exists:   hasContent(n, lang, version),
+		Node:     n,
+	}
+	if variation := getBestVariation(n.Variations, lang, version); variation != nil {
+		item.Title = variation.Title
+		item.External = variation.FrontMatter.Link != ""
+		item.Separator = variation.FrontMatter.Separator
+	}
+	return item
+}
+
+// getTree returns the hierarchy under root, `{version}` nodes are flattened
+func getTree(root, target *Node, lang, version string, options TreeOptions) []*NavItem {
+	return getTreeDepth(root, target, lang, version, 0, options)
+}
+
+func getTreeDepth(root, target *Node, lang, version string, depth int, options TreeOptions) []*NavItem {
+
+	nodesToParent := []*Node{}
+	n := target
+	for n != nil {
+		nodesToParent = append(nodesToParent, n)
+		n = n.Parent
+	}
+
+	result := []*NavItem{}
+
+	for _, child := range root.Children {
+
+		if child.Name == "{version}" {
+			result = append(result, getTreeDepth(child, target, lang, version, depth, options)...)
+			continue
+		}
+
+		if isHidden(child, lang, version) {
+			continue
+		}
+
+		item := newNavItem(child, lang, version)
+		item.Active = nodeIn(nodesToParent, child)
+		item.Selected = child == target
+		item.Depth = depth
+		item.Children = getTreeDepth(child, target, lang, version, depth+1, options)
+		item.HasChildren = len(item.Children) > 0
+		if !options.expand(item) {
+			item.Children = nil
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func getBreadcrumb(n *Node, lang, version string) []*NavItem {
+	breadcrumb := []*Node{}
+
+	for n != nil && len(n.Variations) > 0 {
+		if n.Parent == nil {
+			break
+		}
+		breadcrumb = append(breadcrumb, n)
+		n = n.Parent
+	}
+
+	if len(breadcrumb) < 2 {
+		return nil
+	}
+
+	slices.Reverse(breadcrumb)
+
+	result := []*NavItem{}
+	for i, node := range breadcrumb {
+		if node.Name == "{version}" || !isPage(node, lang, version) {
+			continue
+		}
+		item := newNavItem(node, lang, version)
+		item.Active = true
+		item.Selected = i == len(breadcrumb)-1
+		item.Depth = len(result)
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func getLanguageMenu(n *Node, lang, version string) []*NavItem {
+	result := []*NavItem{}
+	for _, l := range languages {
+		item := newNavItem(n, l, version)
+		item.Title = languageName(l)
+		item.Selected = l == lang
+		item.Active = item.Selected
+		result = append(result, item)
+	}
+	return result
+}
+
+func getVersionMenu(n *Node, lang, version string) []*NavItem {
+	if !hasVersions(n) {
+		return nil
+	}
+
+	result := []*NavItem{}
+	for _, v := range versions {
+		item := newNavItem(n, lang, v)
+		item.Title = v
+		item.Selected = v == version
+		item.Active = item.Selected
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package holadoc
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v1", "v1", 0},
+		{"v1", "v2", -1},
+		{"v2", "v1", 1},
+		{"v2", "v10", -1},
+		{"v1.2", "v1.10", -1},
+		{"v1.10.3", "v1.10", 1},
+		{"v1", "v1.0", 0},
+		{"V1", "v1", 0},
+		{"1.2", "v1.2", 0},
+		{"v1-beta", "v1-rc", -1},
+		{"v2", "v1-rc", 1},
+	}
+
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestHasContent(t *testing.T) {
+
+	node := &Node{Variations: []*Variation{
+		{Language: "en", Version: "v1"},
+		{Language: "es", Version: "v2"},
+		{Language: "zh"},
+	}}
+
+	cases := []struct {
+		lang, version string
+		exact, upTo   bool
+	}{
+		{"en", "v1", true, true},
+		{"en", "v2", false, true},
+		{"en", "", true, true},
+		{"es", "v1", false, false},
+		{"es", "v2", true, true},
+		{"es", "v3", false, true},
+		{"zh", "v7", true, true},
+		{"fr", "v1", false, false},
+	}
+
+	for _, c := range cases {
+		if got := hasContent(node, c.lang, c.version); got != c.exact {
+			t.Errorf("hasContent(%q, %q) = %v, want %v", c.lang, c.version, got, c.exact)
+		}
+		if c.version == "" {
+			continue
+		}
+		if got := hasContentUpTo(node, c.lang, c.version); got != c.upTo {
+			t.Errorf("hasContentUpTo(%q, %q) = %v, want %v", c.lang, c.version, got, c.upTo)
+		}
+	}
+}
+
+func TestGetTreeItems(t *testing.T) {
+
+	defer func(l, v []string, d bool, n time.Time) { languages, versions, drafts, now = l, v, d, n }(languages, versions, drafts, now)
+	languages = []string{"en"}
+	versions = []string{""}
+	drafts = false
+	now = time.Now()
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"10_docs/docs_en.md":               "# Docs\n",
+		"10_docs/05_basics/basics_en.link": "title: Basics\n",
+		"10_docs/10_intro/intro_en.md":     "# Intro\n",
+		"10_docs/20_secret/secret_en.md":   "---\nhidden: true\n---\n# Secret\n",
+		"10_docs/30_github/github_en.link": "title: GitHub\nlink: https://github.com/fulldump/holadoc\n",
+	})
+
+	root := &Node{}
+	readNodes(root, src, t.TempDir())
+
+	items := getTree(getNode(root, "docs"), getNode(root, "docs/intro"), "en", "", TreeOptions{})
+
+	want := []string{
+		"Basics  separator",
+		"Intro /docs/intro/index.html selected",
+		"GitHub https://github.com/fulldump/holadoc external",
+	}
+	got := []string{}
+	for _, item := range items {
+		kind := ""
+		switch {
+		case item.Separator:
+			kind = "separator"
+		case item.External:
+			kind = "external"
+		case item.Selected:
+			kind = "selected"
+		}
+		url := item.Url
+		if item.Separator {
+			url = ""
+		}
+		got = append(got, item.Title+" "+url+" "+kind)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tree = %q, want %q", got, want)
+	}
+
+	// hidden pages are still built and reachable by path
+	if secret := getNode(root, "docs/secret"); secret == nil || !isHidden(secret, "en", "") || !isPage(secret, "en", "") {
+		t.Errorf("docs/secret is not a hidden page")
+	}
+	if github := getNode(root, "docs/github"); github == nil || isPage(github, "en", "") {
+		t.Errorf("docs/github is not an external link")
+	}
+}
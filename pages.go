@@ -0,0 +1,290 @@
+package holadoc
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Page is a node seen from a language and a version, it is what templates
+// get from the page query functions: page, children, siblings, parent...
+type Page struct {
+	Title       string
+	Description string
+	Url         string
+	Language    string
+	Version     string
+	Path        string // node path, the same used by `page "path"`
+	Order       int
+	Selected    bool // the page being rendered
+	Active      bool // the page being rendered is this page or a descendant
+	Exists      bool // has its own content for Language and Version (no fallback)
+	Hidden      bool // not listed, see FrontMatter.Hidden
+	External    bool // Url points outside the site
+	Separator   bool // section header without Url
+	FrontMatter *FrontMatter
+	Params      map[string]any
+	Node        *Node
+}
+
+func newPage(n, current *Node, lang, version string) *Page {
+	if n == nil {
+		return nil
+	}
+
+	variation := getBestVariation(n.Variations, lang, version)
+	if isAutoSection(n, lang, version) {
+		variation = newSectionVariation(n, lang, version)
+	}
+	if variation == nil {
+		return nil
+	}
+
+	return &Page{
+		Title:       variation.Title,
+		Description: variation.Description,
+		Url:         getLink(n, lang, version),
+		Language:    lang,
+		Version:     version,
+		Path:        getNodePath(n),
+		Order:       n.Order,
+		Selected:    n == current,
+		Active:      isUnder(current, n),
+		Exists:      hasContent(n, lang, version),
+		Hidden:      variation.FrontMatter.Hidden,
+		External:    variation.FrontMatter.Link != "",
+		Separator:   variation.FrontMatter.Separator,
+		FrontMatter: variation.FrontMatter,
+		Params:      variation.FrontMatter.Params,
+		Node:        n,
+	}
+}
+
+// getNodePath returns the path of a node, the inverse of getNode
+func getNodePath(n *Node) string {
+	parts := []string{}
+	for n != nil && n.Parent != nil {
+		parts = append([]string{n.Name}, parts...)
+		n = n.Parent
+	}
+	return strings.Join(parts, "/")
+}
+
+// getSubpageLink returns the link of a page generated under a node:
+// blog archives, taxonomy terms...
+func getSubpageLink(n *Node, lang, version string, parts ...string) string {
+	dir := path.Dir(getLink(n, lang, version))
+	return path.Join(slices.Concat([]string{dir}, parts, []string{"index.html"})...)
+}
+
+func getSubpageOutputPath(n *Node, variation *Variation, lang, version string, parts ...string) string {
+	dir := path.Dir(getOutputPath(n, variation, lang, version))
+	return path.Join(slices.Concat([]string{dir}, parts, []string{"index.html"})...)
+}
+
+// slugify turns a name into a url segment: Ana Pérez is ana-perez. Only a-z,
+// 0-9 and - are kept so slugs are safe in paths and urls (C/C++ is c-c),
+// names without any of them (搜索) get a hash.
+func slugify(name string) string {
+
+	name = strings.Join(strings.Fields(strings.ToLower(name)), " ")
+
+	slug := strings.Builder{}
+	dash := false
+	for _, r := range foldAccents(name) {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			dash = true
+			continue
+		}
+		if dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+		}
+		dash = false
+		slug.WriteRune(r)
+	}
+
+	if slug.Len() == 0 {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		return fmt.Sprintf("%08x", h.Sum32())
+	}
+
+	return slug.String()
+}
+
+// getParent returns the parent of a node skipping `{version}` nodes
+func getParent(n *Node) *Node {
+	parent := n.Parent
+	for parent != nil && parent.Name == "{version}" {
+		parent = parent.Parent
+	}
+	if parent == nil || parent.Parent == nil {
+		return nil // the root is not a page
+	}
+	return parent
+}
+
+// getChildren returns the children of a node, `{version}` nodes are flattened
+func getChildren(n *Node) []*Node {
+	result := []*Node{}
+	for _, child := range n.Children {
+		if child.Name == "{version}" {
+			result = append(result, getChildren(child)...)
+			continue
+		}
+		result = append(result, child)
+	}
+	return result
+}
+
+// getAncestors returns the ancestors of a node from the top most to the parent
+func getAncestors(n *Node) []*Node {
+	result := []*Node{}
+	for p := getParent(n); p != nil; p = getParent(p) {
+		result = append(result, p)
+	}
+	slices.Reverse(result)
+	return result
+}
+
+// getReadingOrder returns the nodes under root depth first, following Order
+// and only those with content for lang and version. Hidden nodes and their
+// descendants, external links and separators are left out.
+func getReadingOrder(root *Node, lang, version string) []*Node {
+	result := []*Node{}
+	for _, child := range getChildren(root) {
+		if isHidden(child, lang, version) {
+			continue
+		}
+		if hasContentUpTo(child, lang, version) && isPage(child, lang, version) {
+			result = append(result, child)
+		}
+		result = append(result, getReadingOrder(child, lang, version)...)
+	}
+	return result
+}
+
+// scope of the reading order, see Config.ReadingOrder
+var readingOrder = "site"
+
+var readingOrders = []string{"site", "section", "version"}
+
+// getReadingRoot returns the node that contains the reading order of n
+func getReadingRoot(root, n *Node) *Node {
+
+	switch readingOrder {
+	case "version":
+		for p := n; p != nil; p = p.Parent {
+			if p.Name == "{version}" {
+				return p
+			}
+		}
+		fallthrough
+	case "section":
+		for p := n; p != nil; p = p.Parent {
+			if p.Parent == root {
+				return p
+			}
+		}
+	}
+
+	return root
+}
+
+// getPrevNext returns the neighbors of n in reading order
+func getPrevNext(root, n *Node, lang, version string) (prev, next *Node) {
+
+	scope := getReadingRoot(root, n)
+
+	order := []*Node{}
+	if scope != root && scope.Name != "{version}" && hasContentUpTo(scope, lang, version) && isPage(scope, lang, version) {
+		order = append(order, scope)
+	}
+	order = append(order, getReadingOrder(scope, lang, version)...)
+
+	i := slices.Index(order, n)
+	if i < 0 {
+		return nil, nil
+	}
+	if i > 0 {
+		prev = order[i-1]
+	}
+	if i < len(order)-1 {
+		next = order[i+1]
+	}
+	return
+}
+
+// getField returns a field of a page, `Params.color` or `color` lookup params
+func getField(p *Page, field string) any {
+
+	if key, ok := strings.CutPrefix(field, "Params."); ok {
+		return p.Params[strings.ToLower(key)]
+	}
+
+	v := reflect.ValueOf(p).Elem().FieldByName(field)
+	if !v.IsValid() {
+		return p.Params[strings.ToLower(field)]
+	}
+	return v.Interface()
+}
+
+// wherePages filters pages by a field: `where (children) "Exists" true`
+func wherePages(pages []*Page, field string, value any) []*Page {
+	result := []*Page{}
+	for _, p := range pages {
+		v := getField(p, field)
+		if s, ok := v.([]string); ok {
+			if in(s, fmt.Sprint(value)) {
+				result = append(result, p)
+			}
+			continue
+		}
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// sortPages sorts pages by a field, add "desc" to reverse the order:
+// `sortBy (children) "Title" "desc"`
+func sortPages(pages []*Page, field string, order ...string) []*Page {
+	result := slices.Clone(pages)
+	slices.SortStableFunc(result, func(a, b *Page) int {
+		return compareValues(getField(a, field), getField(b, field))
+	})
+	if len(order) > 0 && strings.EqualFold(order[0], "desc") {
+		slices.Reverse(result)
+	}
+	return result
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		if b, ok := b.(int); ok {
+			return cmp.Compare(a, b)
+		}
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmp.Compare(a, b)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// firstPages returns at most n pages: `first 3 (children)`
+func firstPages(n int, pages []*Page) []*Page {
+	if n < 0 {
+		return []*Page{}
+	}
+	if n < len(pages) {
+		return pages[:n]
+	}
+	return pages
+}
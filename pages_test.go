@@ -0,0 +1,100 @@
+package holadoc
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"go", "go"},
+		{"Ana Pérez", "ana-perez"},
+		{"  Hello   World ", "hello-world"},
+		{"C/C++", "c-c"},
+		{"../../../escape", "escape"},
+		{"v1.2", "v1-2"},
+		{"-release-", "release"},
+		{"Año 2024!", "ano-2024"},
+	}
+
+	for _, c := range cases {
+		if got := slugify(c.name); got != c.want {
+			t.Errorf("slugify(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSlugifyFallback(t *testing.T) {
+
+	hex := regexp.MustCompile(`^[0-9a-f]{8}$`)
+
+	slugs := map[string]string{}
+	for _, name := range []string{"?", "..", "搜索", "索引", "/"} {
+		got := slugify(name)
+		if !hex.MatchString(got) {
+			t.Errorf("slugify(%q) = %q, want 8 hex digits", name, got)
+		}
+		if got != slugify(name) {
+			t.Errorf("slugify(%q) is not stable", name)
+		}
+		if other, ok := slugs[got]; ok {
+			t.Errorf("slugify(%q) = slugify(%q) = %q", name, other, got)
+		}
+		slugs[got] = name
+	}
+
+	if slugify("搜索") != slugify(" 搜索 ") {
+		t.Errorf("slugify(%q) != slugify(%q)", "搜索", " 搜索 ")
+	}
+}
+
+func TestPageQueries(t *testing.T) {
+
+	pages := []*Page{
+		{Title: "Install", Order: 20, Params: map[string]any{"weight": 2, "color": "red"}},
+		{Title: "Config", Order: 10, Exists: true, Params: map[string]any{"weight": 10}},
+		{Title: "API", Order: 30, FrontMatter: &FrontMatter{Tags: []string{"go"}}, Params: map[string]any{"weight": 1.5, "color": "blue"}},
+	}
+
+	titles := func(pages []*Page) string {
+		result := []string{}
+		for _, p := range pages {
+			result = append(result, p.Title)
+		}
+		return strings.Join(result, ",")
+	}
+
+	cases := []struct {
+		name  string
+		pages []*Page
+		want  string
+	}{
+		{"first 2", firstPages(2, pages), "Install,Config"},
+		{"first 5", firstPages(5, pages), "Install,Config,API"},
+		{"first 0", firstPages(0, pages), ""},
+		{"first -1", firstPages(-1, pages), ""},
+		{"sortBy Title", sortPages(pages, "Title"), "API,Config,Install"},
+		{"sortBy Order desc", sortPages(pages, "Order", "desc"), "API,Install,Config"},
+		{"sortBy Params.weight", sortPages(pages[:2], "Params.weight"), "Install,Config"}, // numbers, not text
+		{"sortBy color", sortPages(pages, "color"), "Config,API,Install"},
+		{"where Exists", wherePages(pages, "Exists", true), "Config"},
+		{"where Params.color", wherePages(pages, "Params.color", "red"), "Install"},
+		{"where Color", wherePages(pages, "Color", "blue"), "API"}, // params are lowercase
+		{"where missing", wherePages(pages, "Title", "Nothing"), ""},
+	}
+
+	for _, c := range cases {
+		if got := titles(c.pages); got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	if got := titles(pages); got != "Install,Config,API" {
+		t.Errorf("sortBy changed the original order: %q", got)
+	}
+}
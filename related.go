@@ -0,0 +1,217 @@
+package holadoc
+
+import (
+	"cmp"
+	"math"
+	"path"
+	"slices"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// relatedLimit is the default number of related pages of a page, site.json
+// can change it with `related_limit`
+const relatedLimit = 5
+
+// relatedMinScore leaves out pages that only share a few common words
+const relatedMinScore = 0.05
+
+// weights of a term depending on where it is found, tags and categories are
+// terms of their own
+const (
+	relatedTitleWeight   = 3
+	relatedHeadingWeight = 2
+	relatedBodyWeight    = 1
+	relatedTermWeight    = 3
+)
+
+// Related finds the pages similar to a page comparing the TF-IDF vectors of
+// their titles, headings, content, tags and categories. Only pages of the
+// same language and version are compared.
+type Related struct {
+	limit   int
+	tfs     map[string]map[string]float64 // term frequencies by lang and filename
+	indexes map[string]*relatedIndex      // by lang/version
+}
+
+type relatedIndex struct {
+	nodes   []*Node
+	vectors []map[string]float64 // tf-idf, normalized
+}
+
+func newRelated(site *Site) *Related {
+	return &Related{
+		limit:   cmp.Or(site.RelatedLimit, relatedLimit),
+		tfs:     map[string]map[string]float64{},
+		indexes: map[string]*relatedIndex{},
+	}
+}
+
+// get returns the pages related to node, most similar first. The page itself
+// and its ancestors are left out.
+func (r *Related) get(root, node *Node, lang, version string) []*Page {
+
+	if r.limit < 0 {
+		return []*Page{} // disabled
+	}
+
+	idx := r.getIndex(root, lang, version)
+
+	current := slices.Index(idx.nodes, node)
+	if current < 0 {
+		return []*Page{}
+	}
+
+	ancestors := getAncestors(node)
+
+	type candidate struct {
+		node  *Node
+		score float64
+	}
+	candidates := []candidate{}
+	for i, n := range idx.nodes {
+		if i == current || slices.Contains(ancestors, n) {
+			continue
+		}
+		// rounded, sums in map order differ in the last bits and equal pages
+		// would swap places between builds
+		score := math.Round(similarity(idx.vectors[current], idx.vectors[i])*1e9) / 1e9
+		if score < relatedMinScore {
+			continue
+		}
+		candidates = append(candidates, candidate{node: n, score: score})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.score, a.score)
+	})
+
+	result := []*Page{}
+	for _, c := range candidates {
+		if len(result) == r.limit {
+			break
+		}
+		if page := newPage(c.node, node, lang, version); page != nil {
+			result = append(result, page)
+		}
+	}
+
+	return result
+}
+
+func (r *Related) getIndex(root *Node, lang, version string) *relatedIndex {
+
+	key := path.Join(lang, version)
+	if idx, exists := r.indexes[key]; exists {
+		return idx
+	}
+
+	idx := &relatedIndex{}
+	tfs := []map[string]float64{}
+
+	traverseNodes(root, func(n *Node) {
+		if n.Parent == nil || n.Name == "{version}" {
+			return
+		}
+		if isHidden(n, lang, version) || !isPage(n, lang, version) {
+			return
+		}
+		variation := getBestVariation(n.Variations, lang, version)
+		if variation == nil || variation.Filename == "" || !hasContent(n, lang, version) {
+			return
+		}
+		idx.nodes = append(idx.nodes, n)
+		tfs = append(tfs, r.getTf(variation, lang))
+	})
+
+	// document frequency
+	df := map[string]int{}
+	for _, tf := range tfs {
+		for term := range tf {
+			df[term]++
+		}
+	}
+
+	total := float64(len(tfs))
+	for _, tf := range tfs {
+		vector := map[string]float64{}
+		norm := 0.0
+		for term, frequency := range tf {
+			weight := frequency * math.Log(total/float64(df[term]))
+			if weight == 0 {
+				continue // in every page
+			}
+			vector[term] = weight
+			norm += weight * weight
+		}
+		norm = math.Sqrt(norm)
+		for term := range vector {
+			vector[term] /= norm
+		}
+		idx.vectors = append(idx.vectors, vector)
+	}
+
+	r.indexes[key] = idx
+	return idx
+}
+
+// getTf returns the weighted term frequencies of a source file, analyzed like
+// the search index of lang
+func (r *Related) getTf(variation *Variation, lang string) map[string]float64 {
+
+	key := lang + ":" + variation.Filename
+	if tf, exists := r.tfs[key]; exists {
+		return tf
+	}
+
+	_, htmlReader := readSource(variation.Filename)
+	nodes, err := html.ParseFragment(htmlReader, &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	analyzer := getAnalyzer(lang)
+	tf := map[string]float64{}
+	add := func(text string, weight float64) {
+		for _, term := range analyzer.Analyze(text) {
+			tf[term] += weight
+		}
+	}
+
+	add(variation.Title, relatedTitleWeight)
+	for _, section := range getSearchSections(nodes) {
+		add(section.heading, relatedHeadingWeight)
+		add(section.text, relatedBodyWeight)
+	}
+	for _, taxonomy := range taxonomyNames {
+		for _, name := range getTerms(variation.FrontMatter, taxonomy) {
+			// prefixed, a tag is not the same as the word
+			tf[taxonomy+":"+slugify(name)] += relatedTermWeight
+		}
+	}
+
+	// sublinear, long pages do not win just by repeating words
+	for term, frequency := range tf {
+		tf[term] = 1 + math.Log(frequency)
+	}
+
+	r.tfs[key] = tf
+	return tf
+}
+
+// similarity is the cosine of two normalized vectors
+func similarity(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	result := 0.0
+	for term, weight := range a {
+		result += weight * b[term]
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package holadoc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+
+	cases := []struct {
+		a, b map[string]float64
+		want float64
+	}{
+		{map[string]float64{"index": 1}, map[string]float64{"index": 1}, 1},
+		{map[string]float64{"index": 0.6, "query": 0.8}, map[string]float64{"index": 0.6, "query": 0.8}, 1},
+		{map[string]float64{"index": 1}, map[string]float64{"query": 1}, 0},
+		{map[string]float64{"index": 0.6, "query": 0.8}, map[string]float64{"index": 1}, 0.6},
+		{map[string]float64{"index": 0.6, "query": 0.8}, map[string]float64{"query": 0.6, "tags:go": 0.8}, 0.48},
+		{map[string]float64{}, map[string]float64{"index": 1}, 0},
+		{map[string]float64{}, map[string]float64{}, 0},
+	}
+
+	for _, c := range cases {
+		got := similarity(c.a, c.b)
+		if math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("similarity(%v, %v) = %v, want %v", c.a, c.b, got, c.want)
+		}
+		if reverse := similarity(c.b, c.a); reverse != got {
+			t.Errorf("similarity(%v, %v) = %v, reversed %v", c.a, c.b, got, reverse)
+		}
+	}
+}
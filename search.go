@@ -0,0 +1,248 @@
+package holadoc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// searchDir is where the search indexes are written, one per language and
+// version: `/_search/en/v1/manifest.json`
+const searchDir = "_search"
+
+// SearchIndex is the inverted index of the pages of a language and version.
+// Every page is split in sections by its headings so results link to the
+// exact anchor.
+type SearchIndex struct {
+	Analyzer string           `json:"analyzer"` // queries must be analyzed the same way
+	Docs     []SearchDoc      `json:"docs"`
+	Terms    map[string][]int `json:"terms"` // term: doc, score, doc, score...
+
+	analyzer Analyzer
+}
+
+// SearchDoc is a section of a page
+type SearchDoc struct {
+	Title   string `json:"t"`           // page title
+	Heading string `json:"h,omitempty"` // section heading, empty for the top of the page
+	Url     string `json:"u"`           // page url with the heading anchor
+
+	text string
+}
+
+// scores of a term depending on where it is found
+const (
+	searchTitleScore   = 10
+	searchHeadingScore = 5
+	searchBodyScore    = 1
+)
+
+// searchIndexes being built while rendering, indexed by `lang/version`
+var searchIndexes = map[string]*SearchIndex{}
+
+func getSearchIndex(lang, version string) *SearchIndex {
+	key := path.Join(lang, version)
+	idx, exists := searchIndexes[key]
+	if !exists {
+		analyzer := getAnalyzer(lang)
+		idx = &SearchIndex{
+			Analyzer: analyzer.Name(),
+			Docs:     []SearchDoc{},
+			Terms:    map[string][]int{},
+			analyzer: analyzer,
+		}
+		searchIndexes[key] = idx
+	}
+	return idx
+}
+
+func getSearchIndexLink(lang, version string) string {
+	return path.Join(basepath, searchDir, lang, version, searchManifestFile)
+}
+
+// addPage indexes the rendered content of a page
+func (idx *SearchIndex) addPage(title, link string, nodes []*html.Node) {
+
+	for i, section := range getSearchSections(nodes) {
+
+		doc := SearchDoc{
+			Title:   title,
+			Heading: section.heading,
+			Url:     link,
+			text:    strings.Join(strings.Fields(section.text), " "), // kept for snippets
+		}
+		if section.anchor != "" {
+			doc.Url += "#" + section.anchor
+		}
+
+		id := len(idx.Docs)
+		idx.Docs = append(idx.Docs, doc)
+
+		scores := map[string]int{}
+		if i == 0 {
+			for _, term := range idx.analyzer.Analyze(title) {
+				scores[term] += searchTitleScore
+			}
+		}
+		for _, term := range idx.analyzer.Analyze(section.heading) {
+			scores[term] += searchHeadingScore
+		}
+		for _, term := range idx.analyzer.Analyze(section.text) {
+			scores[term] += searchBodyScore
+		}
+
+		for term, score := range scores {
+			idx.Terms[term] = append(idx.Terms[term], id, score)
+		}
+	}
+}
+
+type searchSection struct {
+	heading string
+	anchor  string
+	text    string
+}
+
+// getSearchSections splits content by h2-h6 headings, code blocks are left
+// out
+func getSearchSections(nodes []*html.Node) []*searchSection {
+
+	sections := []*searchSection{{}}
+	text := &strings.Builder{}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+			return
+		case html.ElementNode:
+			tag := strings.ToLower(n.Data)
+			switch {
+			case in([]string{"script", "style", "pre", "h1"}, tag):
+				return
+			case in([]string{"h2", "h3", "h4", "h5", "h6"}, tag):
+				sections[len(sections)-1].text = text.String()
+				text.Reset()
+				sections = append(sections, &searchSection{
+					heading: strings.TrimSpace(textContent(n)),
+					anchor:  getAttribute(n, "id"),
+				})
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	for _, n := range nodes {
+		walk(n)
+	}
+	sections[len(sections)-1].text = text.String()
+
+	return sections
+}
+
+// searchShardPrefix is the number of runes of the term prefix used to split
+// the index in shards, CJK terms use a single rune
+const searchShardPrefix = 2
+
+// searchManifest is the entry point of a sharded search index, the browser
+// only fetches the shards of the terms of the query. Files are relative to
+// the manifest and named by the hash of their content so they can be cached
+// forever.
+type searchManifest struct {
+	Analyzer string            `json:"analyzer"`
+	Prefix   int               `json:"prefix"`
+	Docs     string            `json:"docs"`
+	Shards   map[string]string `json:"shards"` // term prefix: file
+}
+
+func getShardKey(term string) string {
+	runes := []rune(term)
+	n := searchShardPrefix
+	if len(runes) > 0 && isCJK(runes[0]) {
+		n = 1
+	}
+	if len(runes) < n {
+		n = len(runes)
+	}
+	return string(runes[:n])
+}
+
+// writeSearchIndexes writes the index of every language and version to
+// `/_search/{lang}/{version}/manifest.json` and its shards
+func writeSearchIndexes(www string) {
+
+	for key, idx := range searchIndexes {
+
+		dir := path.Join(www, searchDir, key)
+		err := os.MkdirAll(dir, 0777)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		shards := map[string]map[string][]int{}
+		for term, postings := range idx.Terms {
+			shardKey := getShardKey(term)
+			if shards[shardKey] == nil {
+				shards[shardKey] = map[string][]int{}
+			}
+			shards[shardKey][term] = postings
+		}
+
+		manifest := &searchManifest{
+			Analyzer: idx.Analyzer,
+			Prefix:   searchShardPrefix,
+			Docs:     writeHashedJSON(dir, "docs.", idx.Docs),
+			Shards:   map[string]string{},
+		}
+		for shardKey, terms := range shards {
+			manifest.Shards[shardKey] = writeHashedJSON(dir, "", terms)
+		}
+
+		writeJSON(path.Join(dir, searchManifestFile), manifest)
+	}
+}
+
+const searchManifestFile = "manifest.json"
+
+// writeHashedJSON writes v to dir with the hash of its content as name and
+// returns the name
+func writeHashedJSON(dir, prefix string, v any) string {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	sum := sha256.Sum256(data)
+	name := prefix + hex.EncodeToString(sum[:6]) + ".json"
+
+	err = os.WriteFile(path.Join(dir, name), data, 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return name
+}
+
+func writeJSON(filename string, v any) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	err = os.WriteFile(filename, data, 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+}
@@ -0,0 +1,62 @@
+package holadoc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetShardKey(t *testing.T) {
+
+	cases := []struct {
+		term string
+		want string
+	}{
+		{"collection", "co"},
+		{"go", "go"},
+		{"a", "a"},
+		{"", ""},
+		{"indice", "in"},
+		{"ñandu", "ña"},
+		{"索引", "索"},
+		{"索", "索"},
+		{"검색", "검"},
+	}
+
+	for _, c := range cases {
+		if got := getShardKey(c.term); got != c.want {
+			t.Errorf("getShardKey(%q) = %q, want %q", c.term, got, c.want)
+		}
+	}
+}
+
+func TestSearchOwnLanguage(t *testing.T) {
+
+	defer func(l, v []string, u string) { languages, versions, baseurl = l, v, u }(languages, versions, baseurl)
+
+	src, www := t.TempDir(), t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"10_install/install_en.md": "# Install\n\nDownload the binary\n",
+		"10_install/install_zh.md": "# 安装\n\n下载二进制文件\n",
+		"20_config/config_en.md":   "# Config\n\nEdit the collection\n",
+	})
+
+	HolaDoc(Config{Src: src, Www: www, Languages: "en,zh", Versions: ""})
+
+	cases := []struct {
+		lang   string
+		titles []string
+	}{
+		{"en", []string{"Install", "Config"}},
+		{"zh", []string{"安装"}}, // config is only written in english
+	}
+
+	for _, c := range cases {
+		titles := []string{}
+		for _, doc := range getSearchIndex(c.lang, "").Docs {
+			titles = append(titles, doc.Title)
+		}
+		if !slices.Equal(titles, c.titles) {
+			t.Errorf("index %s has %q, want %q", c.lang, titles, c.titles)
+		}
+	}
+}
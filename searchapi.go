@@ -0,0 +1,286 @@
+package holadoc
+
+import (
+	"cmp"
+	"encoding/json"
+	"html"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchResult is a section of a page found by Search
+type SearchResult struct {
+	Title   string `json:"title"`
+	Heading string `json:"heading,omitempty"`
+	Url     string `json:"url"`
+	Score   int    `json:"score"`
+	Snippet string `json:"snippet"` // html, matches are wrapped in <mark>
+}
+
+// snippetLength is the approximate number of runes of a snippet
+const snippetLength = 200
+
+// Search looks for query in the pages rendered by the last HolaDoc run for
+// lang and version. Results contain all the words of the query, the last one
+// can be incomplete, and are sorted by score.
+func Search(query, lang, version string, limit int) []*SearchResult {
+
+	idx, exists := searchIndexes[path.Join(lang, version)]
+	if !exists {
+		return []*SearchResult{}
+	}
+
+	tokens := idx.analyzer.Analyze(query)
+	if len(tokens) == 0 {
+		return []*SearchResult{}
+	}
+
+	var scores map[int]int
+	for i, token := range tokens {
+
+		terms := []string{token}
+		if i == len(tokens)-1 {
+			terms = idx.getTermsWithPrefix(token)
+		}
+
+		matches := map[int]int{}
+		for _, term := range terms {
+			postings := idx.Terms[term]
+			for j := 0; j+1 < len(postings); j += 2 {
+				score := postings[j+1]
+				if term == token {
+					score *= 2 // complete words first
+				}
+				matches[postings[j]] += score
+			}
+		}
+
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		for doc, score := range scores {
+			if match, ok := matches[doc]; ok {
+				scores[doc] = score + match
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	docs := make([]int, 0, len(scores))
+	for doc := range scores {
+		docs = append(docs, doc)
+	}
+	slices.SortFunc(docs, func(a, b int) int {
+		if c := cmp.Compare(scores[b], scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	if limit > 0 && len(docs) > limit {
+		docs = docs[:limit]
+	}
+
+	prefix := tokens[len(tokens)-1]
+	results := []*SearchResult{}
+	for _, id := range docs {
+		doc := idx.Docs[id]
+		results = append(results, &SearchResult{
+			Title:   doc.Title,
+			Heading: doc.Heading,
+			Url:     doc.Url,
+			Score:   scores[id],
+			Snippet: getSnippet(idx.analyzer, doc.text, tokens, prefix),
+		})
+	}
+
+	return results
+}
+
+func (idx *SearchIndex) getTermsWithPrefix(prefix string) []string {
+	result := []string{}
+	for term := range idx.Terms {
+		if strings.HasPrefix(term, prefix) {
+			result = append(result, term)
+		}
+	}
+	return result
+}
+
+// span is a match in a text, byte offsets
+type span struct {
+	start, end int
+}
+
+// getSnippet returns the html of the part of text around the first match,
+// with every match highlighted
+func getSnippet(analyzer Analyzer, text string, terms []string, prefix string) string {
+
+	matches := findMatches(analyzer, text, terms, prefix)
+
+	start, end := 0, len(text)
+	if len(matches) > 0 {
+		start = matches[0].start
+		// some context before the match, from the beginning of a word
+		for i := 0; i < snippetLength/4 && start > 0; i++ {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+		if space := strings.IndexByte(text[start:matches[0].start], ' '); start > 0 && space >= 0 {
+			start += space + 1
+		}
+	}
+	if utf8.RuneCountInString(text[start:]) > snippetLength {
+		end = start
+		for i := 0; i < snippetLength; i++ {
+			_, size := utf8.DecodeRuneInString(text[end:])
+			end += size
+		}
+		if space := strings.LastIndexByte(text[start:end], ' '); space > 0 {
+			end = start + space
+		}
+	}
+
+	b := &strings.Builder{}
+	if start > 0 {
+		b.WriteString("…")
+	}
+	offset := start
+	for _, m := range matches {
+		if m.start < offset || m.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[offset:m.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString("</mark>")
+		offset = m.end
+	}
+	b.WriteString(html.EscapeString(text[offset:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// findMatches returns the words of text whose terms are in terms or start
+// with prefix. CJK characters are matched as unigrams and bigrams.
+func findMatches(analyzer Analyzer, text string, terms []string, prefix string) []span {
+
+	matches := []span{}
+
+	isMatch := func(word string) bool {
+		for _, term := range analyzer.Analyze(word) {
+			if in(terms, term) || strings.HasPrefix(term, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	wordStart := -1
+	for i, r := range text {
+
+		isWord := (unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)) && !isCJK(r)
+		if isWord {
+			if wordStart < 0 {
+				wordStart = i
+			}
+			continue
+		}
+
+		if wordStart >= 0 {
+			if isMatch(text[wordStart:i]) {
+				matches = append(matches, span{wordStart, i})
+			}
+			wordStart = -1
+		}
+
+		if !isCJK(r) {
+			continue
+		}
+		size := utf8.RuneLen(r)
+		next, nextSize := utf8.DecodeRuneInString(text[i+size:])
+		if isCJK(next) && isMatch(text[i:i+size+nextSize]) {
+			matches = append(matches, span{i, i + size + nextSize})
+		} else if in(terms, string(r)) {
+			matches = append(matches, span{i, i + size})
+		}
+	}
+	if wordStart >= 0 && isMatch(text[wordStart:]) {
+		matches = append(matches, span{wordStart, len(text)})
+	}
+
+	// overlapping bigrams are merged
+	merged := []span{}
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, m.end)
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	return merged
+}
+
+// searchLimit is the default and maximum number of results of SearchHandler
+const (
+	searchLimit    = 10
+	searchMaxLimit = 50
+)
+
+// SearchHandler serves `/_search?q=&lang=&version=&limit=` with the results
+// of Search as json. It is used in serve mode, see Config.Serve.
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+
+	lang := query.Get("lang")
+	if lang == "" {
+		lang = languages[0]
+	}
+	version := query.Get("version")
+	if version == "" {
+		version = versions[0]
+	}
+
+	if !in(languages, lang) {
+		writeSearchError(w, "unknown lang '"+lang+"'")
+		return
+	}
+	if !in(versions, version) {
+		writeSearchError(w, "unknown version '"+version+"'")
+		return
+	}
+
+	limit := searchLimit
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = min(l, searchMaxLimit)
+	}
+
+	results := Search(query.Get("q"), lang, version, limit)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(map[string]any{
+		"query":   query.Get("q"),
+		"lang":    lang,
+		"version": version,
+		"results": results,
+	})
+}
+
+func writeSearchError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": message,
+	})
+}
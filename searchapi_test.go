@@ -0,0 +1,50 @@
+package holadoc
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGetSnippet(t *testing.T) {
+
+	cases := []struct {
+		lang   string
+		text   string
+		terms  []string
+		prefix string
+		want   string
+	}{
+		{"en", "Create an index on the collection", []string{"index"}, "index", "Create an <mark>index</mark> on the collection"},
+		{"en", "Create collections", []string{"col"}, "col", "Create <mark>collections</mark>"},
+		{"en", "Indexes & <tags>", []string{"index"}, "index", "<mark>Indexes</mark> &amp; &lt;tags&gt;"},
+		{"en", "Nothing to see", []string{"index"}, "index", "Nothing to see"},
+		{"en", "index and index", []string{"index"}, "index", "<mark>index</mark> and <mark>index</mark>"},
+		{"es", "Los índices", []string{"indic"}, "indic", "Los <mark>índices</mark>"},
+		{"zh", "创建索引类型", []string{"索引"}, "索引", "创建<mark>索引</mark>类型"},
+	}
+
+	for _, c := range cases {
+		got := getSnippet(getAnalyzer(c.lang), c.text, c.terms, c.prefix)
+		if got != c.want {
+			t.Errorf("%s: getSnippet(%q, %q) = %q, want %q", c.lang, c.text, c.terms, got, c.want)
+		}
+	}
+}
+
+func TestGetSnippetLong(t *testing.T) {
+
+	text := strings.Repeat("word ", 100) + "needle " + strings.Repeat("word ", 100)
+
+	got := getSnippet(getAnalyzer("en"), text, []string{"needl"}, "needl")
+
+	if !strings.HasPrefix(got, "…word ") || !strings.HasSuffix(got, "word…") {
+		t.Errorf("getSnippet is not cut at words with ellipsis: %q", got)
+	}
+	if !strings.Contains(got, " <mark>needle</mark> ") {
+		t.Errorf("getSnippet does not highlight the match: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n > snippetLength+len("<mark></mark>")+2 {
+		t.Errorf("getSnippet has %d runes, want at most about %d", n, snippetLength)
+	}
+}
@@ -0,0 +1,68 @@
+package holadoc
+
+// sectionLayout is the layout of the pages generated for sections without
+// content, Src can override it with its own `layouts/section.gohtml`
+const sectionLayout = "section"
+
+// isAutoSection returns true if the node gets a generated page listing its
+// children because it has no content for lang and version. A static
+// src/index.html is never replaced.
+func isAutoSection(n *Node, lang, version string) bool {
+	if n.Name == "{version}" || n.staticIndex || hasContentUpTo(n, lang, version) || !isPage(n, lang, version) {
+		return false
+	}
+	return len(getSectionChildren(n, lang, version)) > 0
+}
+
+// getSectionChildren returns the children listed by a generated section page,
+// hidden pages and separators are left out
+func getSectionChildren(n *Node, lang, version string) []*Node {
+	result := []*Node{}
+	for _, child := range getChildren(n) {
+		if isHidden(child, lang, version) {
+			continue
+		}
+		variation := getBestVariation(child.Variations, lang, version)
+		if (variation != nil && !variation.FrontMatter.Separator) || isAutoSection(child, lang, version) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// newSectionVariation returns the variation rendered for a generated section
+// page. The title falls back to other languages like the tree does.
+func newSectionVariation(n *Node, lang, version string) *Variation {
+
+	title := n.Name
+	if best := getBestVariation(n.Variations, lang, version); best != nil {
+		title = best.Title
+	}
+	if n.Parent == nil {
+		title = translate(lang, "home")
+	}
+
+	return &Variation{
+		Url:      n.Name,
+		Language: lang,
+		Version:  version,
+		Title:    title,
+		FrontMatter: &FrontMatter{
+			Layout: getSectionLayout(n),
+			Params: map[string]any{},
+		},
+	}
+}
+
+// getSectionLayout returns the layout of a generated section page: `section`
+// if Src provides it or the site has no template of its own. Otherwise the
+// inherited template keeps sites written before section pages as they were.
+func getSectionLayout(n *Node) string {
+	if layout := findLayout(n, sectionLayout); layout != "" && !isThemeFile(layout) {
+		return sectionLayout
+	}
+	if filename := findTemplate(n); filename != "" && !isThemeFile(filename) {
+		return ""
+	}
+	return sectionLayout
+}
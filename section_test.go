@@ -0,0 +1,68 @@
+package holadoc
+
+import "testing"
+
+func TestIsAutoSection(t *testing.T) {
+
+	page := func(lang string, frontMatter *FrontMatter) *Variation {
+		return &Variation{Language: lang, Filename: "page.md", FrontMatter: frontMatter}
+	}
+	newNode := func(name string, children []*Node, variations ...*Variation) *Node {
+		n := &Node{Name: name, Children: children, Variations: variations}
+		for _, child := range children {
+			child.Parent = n
+		}
+		return n
+	}
+
+	home := newNode("", []*Node{newNode("docs", nil, page("en", &FrontMatter{}))})
+	home.staticIndex = true
+
+	cases := []struct {
+		name string
+		node *Node
+		want bool
+	}{
+		{"without content", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))}), true},
+		{"with content", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))}, page("en", &FrontMatter{})), false},
+		{"content in another language", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))}, page("es", &FrontMatter{})), true},
+		{"without children", newNode("docs", nil), false},
+		{"only hidden children", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{Hidden: true}))}), false},
+		{"only separators", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{Separator: true}))}), false},
+		{"external link", newNode("docs", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))}, page("es", &FrontMatter{Link: "https://hola.cloud"})), false},
+		{"auto section children", newNode("docs", []*Node{newNode("api", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))})}), true},
+		{"version", newNode("{version}", []*Node{newNode("intro", nil, page("en", &FrontMatter{}))}), false},
+		{"static home", home, false},
+	}
+
+	for _, c := range cases {
+		if got := isAutoSection(c.node, "en", ""); got != c.want {
+			t.Errorf("%s: isAutoSection = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestGetSectionLayout(t *testing.T) {
+
+	theme := map[string]string{sectionLayout: themePrefix + "default/layouts/section.gohtml"}
+
+	cases := []struct {
+		name     string
+		template string // of the root
+		layouts  map[string]string
+		want     string
+	}{
+		{"theme only", themePrefix + "default/template.gohtml", theme, sectionLayout},
+		{"site template", "src/template.gohtml", theme, ""},
+		{"site section layout", "src/template.gohtml", map[string]string{sectionLayout: "src/layouts/section.gohtml"}, sectionLayout},
+		{"no template", "", theme, sectionLayout},
+	}
+
+	for _, c := range cases {
+		root := &Node{Template: c.template, Layouts: c.layouts}
+		docs := &Node{Name: "docs", Parent: root}
+		if got := getSectionLayout(docs); got != c.want {
+			t.Errorf("%s: getSectionLayout = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
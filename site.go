@@ -0,0 +1,56 @@
+package holadoc
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// siteFile is the optional configuration of the site, at the root of Src
+const siteFile = "site.json"
+
+// Site is the configuration of a site that does not fit in command line
+// flags: theme, theme parameters...
+type Site struct {
+	Theme       string                  `json:"theme"`        // directory or zip file, relative to Src
+	ThemeParams map[string]any          `json:"theme_params"` // override the theme manifest params
+	Menus       map[string][]*MenuEntry `json:"menus"`        // named menus: main, footer...
+
+	ArchivedVersions []string `json:"archived_versions"` // still built but left out of the sitemap
+	SitemapExclude   []string `json:"sitemap_exclude"`   // node paths left out of the sitemap
+
+	Feeds map[string]any          `json:"feeds"` // node path: true, "full" or "summary", see Feeds
+	Blogs map[string]*BlogOptions `json:"blogs"` // node path: options, see Blogs
+
+	RelatedLimit int `json:"related_limit"` // related pages of every page, 5 by default, -1 disables them
+}
+
+func readSite(src string) *Site {
+
+	site := &Site{
+		ThemeParams: map[string]any{},
+	}
+
+	filename := path.Join(src, siteFile)
+
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return site
+	}
+	if err != nil {
+		panic(err.Error())
+	}
+
+	err = json.Unmarshal(b, site)
+	if err != nil {
+		panic(filename + ": " + err.Error())
+	}
+
+	if site.Theme != "" && !path.IsAbs(site.Theme) {
+		site.Theme = path.Join(src, site.Theme)
+	}
+
+	return site
+}
@@ -0,0 +1,240 @@
+package holadoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// sitemapMaxUrls is the limit of urls of a sitemap file, bigger sites get a
+// sitemap index pointing to several sitemaps
+const sitemapMaxUrls = 50000
+
+// Sitemap collects the pages written while rendering
+type Sitemap struct {
+	urls    []*sitemapUrl
+	written map[string]bool
+
+	archived []string // versions left out
+	exclude  []string // node paths left out, with their descendants
+}
+
+type sitemapUrlset struct {
+	XMLName xml.Name      `xml:"urlset"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Xhtml   string        `xml:"xmlns:xhtml,attr"`
+	Urls    []*sitemapUrl `xml:"url"`
+}
+
+type sitemapUrl struct {
+	Loc     string        `xml:"loc"`
+	Lastmod string        `xml:"lastmod,omitempty"`
+	Links   []sitemapLink `xml:"xhtml:link"`
+}
+
+type sitemapLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	Xmlns    string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapElement `xml:"sitemap"`
+}
+
+type sitemapElement struct {
+	Loc string `xml:"loc"`
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+func newSitemap(site *Site) *Sitemap {
+	return &Sitemap{
+		written:  map[string]bool{},
+		archived: site.ArchivedVersions,
+		exclude:  site.SitemapExclude,
+	}
+}
+
+// add registers a page, drafts, hidden pages, archived versions, excluded
+// paths and pages rendered in a fallback language are left out
+func (s *Sitemap) add(node *Node, variation *Variation, lang, version string) {
+
+	if variation.Draft || !isOwnLanguage(variation, lang) || isHidden(node, lang, version) || in(s.archived, version) {
+		return
+	}
+
+	nodePath := getNodePath(node)
+	for _, exclude := range s.exclude {
+		exclude = strings.Trim(exclude, "/")
+		if nodePath == exclude || strings.HasPrefix(nodePath, exclude+"/") {
+			return
+		}
+	}
+
+	loc := getAbsoluteLink(node, lang, version)
+	if s.written[loc] {
+		return
+	}
+	s.written[loc] = true
+
+	url := &sitemapUrl{
+		Loc: loc,
+	}
+
+	if variation.Filename != "" {
+		url.Lastmod = getLastmod(variation.Filename).Format(time.RFC3339)
+	}
+
+	alternates := getAlternates(node, version)
+	if len(alternates) > 2 { // x-default is always there
+		for _, alternate := range alternates {
+			url.Links = append(url.Links, sitemapLink{
+				Rel:      "alternate",
+				Hreflang: alternate.Language,
+				Href:     alternate.Url,
+			})
+		}
+	}
+
+	s.urls = append(s.urls, url)
+}
+
+// gitDates are the dates of the last commit of the source files, see
+// getGitDates
+var gitDates = map[string]time.Time{}
+
+// getLastmod returns the date of the last commit of a file or its
+// modification time if it is not committed
+func getLastmod(filename string) time.Time {
+
+	if abs, err := filepath.Abs(filename); err == nil {
+		if date, ok := gitDates[abs]; ok {
+			return date
+		}
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return now
+	}
+	return info.ModTime()
+}
+
+// getGitDates returns the date of the last commit of every file under src,
+// indexed by absolute filename. It is empty if src is not in a git repository.
+func getGitDates(src string) map[string]time.Time {
+
+	dates := map[string]time.Time{}
+
+	toplevel, err := exec.Command("git", "-C", src, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return dates
+	}
+	root := strings.TrimSpace(string(toplevel))
+
+	// newest commits first, a file keeps the date of its first appearance
+	output, err := exec.Command("git", "-C", src, "log", "--format=%x00%cI", "--name-only", "--", ".").Output()
+	if err != nil {
+		return dates
+	}
+
+	var date time.Time
+	for _, line := range strings.Split(string(output), "\n") {
+		if d, found := strings.CutPrefix(line, "\x00"); found {
+			date, _ = time.Parse(time.RFC3339, d)
+			continue
+		}
+		if line == "" || date.IsZero() {
+			continue
+		}
+		filename := filepath.Join(root, line)
+		if _, exists := dates[filename]; !exists {
+			dates[filename] = date
+		}
+	}
+
+	return dates
+}
+
+// write writes sitemap.xml, or a sitemap index with sitemap-1.xml,
+// sitemap-2.xml... when there are too many urls
+func (s *Sitemap) write(www string) {
+
+	if baseurl == "" {
+		fmt.Println("WARNING: sitemap.xml is not generated, it needs the site url (--url)")
+		return
+	}
+
+	if len(s.urls) <= sitemapMaxUrls {
+		writeXML(path.Join(www, "sitemap.xml"), s.newUrlset(s.urls))
+		return
+	}
+
+	index := &sitemapIndex{
+		Xmlns: sitemapXmlns,
+	}
+	for i := 0; i*sitemapMaxUrls < len(s.urls); i++ {
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		urls := s.urls[i*sitemapMaxUrls : min((i+1)*sitemapMaxUrls, len(s.urls))]
+		writeXML(path.Join(www, name), s.newUrlset(urls))
+		index.Sitemaps = append(index.Sitemaps, sitemapElement{
+			Loc: strings.TrimSuffix(baseurl, "/") + path.Join(basepath, name),
+		})
+	}
+	writeXML(path.Join(www, "sitemap.xml"), index)
+}
+
+func (s *Sitemap) newUrlset(urls []*sitemapUrl) *sitemapUrlset {
+	return &sitemapUrlset{
+		Xmlns: sitemapXmlns,
+		Xhtml: "http://www.w3.org/1999/xhtml",
+		Urls:  urls,
+	}
+}
+
+// writeRobots writes a robots.txt pointing to the sitemap, unless Src
+// provides its own
+func writeRobots(src, www string) {
+
+	if _, err := os.Stat(path.Join(src, "robots.txt")); err == nil {
+		return
+	}
+
+	robots := "User-agent: *\nAllow: /\n"
+	if baseurl != "" {
+		robots += "\nSitemap: " + strings.TrimSuffix(baseurl, "/") + path.Join(basepath, "sitemap.xml") + "\n"
+	}
+
+	err := os.WriteFile(path.Join(www, "robots.txt"), []byte(robots), 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+func writeXML(filename string, v any) {
+
+	b := &bytes.Buffer{}
+	b.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(b)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	b.WriteString("\n")
+
+	err = os.WriteFile(filename, b.Bytes(), 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+}
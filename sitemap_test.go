@@ -0,0 +1,94 @@
+package holadoc
+
+import (
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestSitemapAdd(t *testing.T) {
+
+	defer func(l, v []string, u string) { languages, versions, baseurl = l, v, u }(languages, versions, baseurl)
+	languages = []string{"en", "es", "zh"}
+	versions = []string{"v2", "v1"}
+	baseurl = "https://hola.cloud"
+
+	root := &Node{}
+	newNode := func(name string, parent *Node, variations ...*Variation) *Node {
+		n := &Node{Name: name, Parent: parent, Variations: variations}
+		parent.Children = append(parent.Children, n)
+		return n
+	}
+	variation := func(url, lang string, frontMatter *FrontMatter) *Variation {
+		return &Variation{Url: url, Language: lang, FrontMatter: frontMatter}
+	}
+
+	install := newNode("install", root, variation("install", "en", &FrontMatter{}), variation("install", "es", &FrontMatter{}))
+	config := newNode("config", root, variation("config", "en", &FrontMatter{}))
+	secret := newNode("secret", root, variation("secret", "en", &FrontMatter{Hidden: true}))
+	legal := newNode("legal", root, variation("legal", "en", &FrontMatter{}))
+	terms := newNode("terms", legal, variation("terms", "en", &FrontMatter{}))
+	draft := newNode("draft", root, &Variation{Url: "draft", Language: "en", FrontMatter: &FrontMatter{}, Draft: true})
+
+	sitemap := newSitemap(&Site{ArchivedVersions: []string{"v1"}, SitemapExclude: []string{"/legal/"}})
+
+	for _, n := range []*Node{install, config, secret, legal, terms, draft} {
+		for _, version := range versions {
+			for _, lang := range languages {
+				sitemap.add(n, getBestVariation(n.Variations, lang, version), lang, version)
+			}
+		}
+	}
+
+	locs := []string{}
+	for _, url := range sitemap.urls {
+		locs = append(locs, url.Loc)
+	}
+	want := []string{
+		"https://hola.cloud/install/index.html",
+		"https://hola.cloud/es/install/index.html",
+		"https://hola.cloud/config/index.html", // not in es or zh, they fall back to en
+	}
+	if !slices.Equal(locs, want) {
+		t.Errorf("sitemap urls = %q, want %q", locs, want)
+	}
+
+	// translated pages list their alternates, x-default included
+	if links := sitemap.urls[0].Links; len(links) != 3 || links[2].Hreflang != "x-default" {
+		t.Errorf("install alternates = %+v", links)
+	}
+	if links := sitemap.urls[2].Links; len(links) != 0 {
+		t.Errorf("config alternates = %+v, want none", links)
+	}
+}
+
+func TestWriteRobots(t *testing.T) {
+
+	defer func(u string) { baseurl = u }(baseurl)
+
+	cases := []struct {
+		baseurl string
+		src     string // src/robots.txt
+		want    string
+	}{
+		{"https://hola.cloud/", "", "User-agent: *\nAllow: /\n\nSitemap: https://hola.cloud/sitemap.xml\n"},
+		{"", "", "User-agent: *\nAllow: /\n"},
+		{"https://hola.cloud", "# Robots File", ""}, // copied from src, not written
+	}
+
+	for _, c := range cases {
+		baseurl = c.baseurl
+		src, www := t.TempDir(), t.TempDir()
+		if c.src != "" {
+			writeFiles(t, src, map[string]string{"robots.txt": c.src})
+		}
+
+		writeRobots(src, www)
+
+		b, _ := os.ReadFile(path.Join(www, "robots.txt"))
+		if string(b) != c.want {
+			t.Errorf("robots.txt with url %q = %q, want %q", c.baseurl, b, c.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package holadoc
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Social is the metadata of a page for Open Graph and Twitter cards, shared
+// links render as cards instead of bare urls
+type Social struct {
+	Type             string // og:type, "article" or "website"
+	Url              string // empty without the site url, see checkSocial
+	Image            string // absolute url, empty without the site url
+	Locale           string
+	LocaleAlternates []string
+	Card             string // twitter:card
+}
+
+// locales used by og:locale, catalogs can override them with the key
+// `language.locale`
+var languageLocales = map[string]string{
+	"ar": "ar_AR",
+	"de": "de_DE",
+	"en": "en_US",
+	"es": "es_ES",
+	"fr": "fr_FR",
+	"he": "he_IL",
+	"it": "it_IT",
+	"ja": "ja_JP",
+	"ko": "ko_KR",
+	"pt": "pt_BR",
+	"ru": "ru_RU",
+	"zh": "zh_CN",
+}
+
+func getLocale(lang string) string {
+
+	if locale, ok := catalogs[lang]["language.locale"]; ok {
+		return locale
+	}
+
+	if locale, ok := languageLocales[lang]; ok {
+		return locale
+	}
+
+	return lang
+}
+
+func getSocial(node *Node, variation *Variation, lang, version, image string) *Social {
+
+	social := &Social{
+		Type:   "article",
+		Url:    getAbsoluteLink(node, lang, version),
+		Image:  image,
+		Locale: getLocale(lang),
+		Card:   "summary",
+	}
+
+	if node.Parent == nil || variation.Filename == "" {
+		social.Type = "website" // home and section pages
+	}
+
+	// Open Graph only accepts absolute urls
+	if baseurl == "" {
+		social.Url = ""
+		social.Image = ""
+	}
+
+	if social.Image != "" {
+		social.Card = "summary_large_image"
+	}
+
+	for _, l := range languages {
+		if l != lang && hasContent(node, l, "") {
+			social.LocaleAlternates = append(social.LocaleAlternates, getLocale(l))
+		}
+	}
+
+	return social
+}
+
+// checkSocial warns that cards and structured data are incomplete without the
+// site url
+func checkSocial() {
+	if baseurl == "" {
+		fmt.Println("WARNING: og:url, og:image and JSON-LD urls are left out, they need the site url (--url)")
+	}
+}
+
+// getImage returns the absolute url of the image of a page: front matter
+// `image` or the first image of the content
+func getImage(variation *Variation, nodes []*html.Node, link string) string {
+
+	src := variation.FrontMatter.Image
+
+	for _, n := range nodes {
+		traverseHtml(n, func(node *html.Node) {
+			if src == "" && node.Data == "img" {
+				src = getAttribute(node, "src")
+			}
+		})
+	}
+
+	if src == "" {
+		return ""
+	}
+
+	return getAbsoluteUrl(src, link)
+}
+
+// getAbsoluteUrl resolves ref from the page at link
+func getAbsoluteUrl(ref, link string) string {
+
+	u, err := url.Parse(ref)
+	if err != nil || u.IsAbs() || u.Host != "" {
+		return ref
+	}
+
+	if strings.HasPrefix(ref, "#") {
+		ref = strings.SplitN(link, "#", 2)[0] + ref
+	} else if !strings.HasPrefix(ref, "/") {
+		ref = path.Join(path.Dir(link), ref)
+	}
+
+	return strings.TrimSuffix(baseurl, "/") + ref
+}
+
+// getArticleType returns the schema.org type of a page: BlogPosting for posts
+// of a blog section and front matter `type: blog`, TechArticle for the rest
+func getArticleType(variation *Variation, post bool) string {
+	if post || variation.FrontMatter.Type == "blog" {
+		return "BlogPosting"
+	}
+	return "TechArticle"
+}
+
+// getStructuredData returns the JSON-LD objects of a page: the article and
+// the breadcrumb. Urls are left out without the site url, schema.org needs
+// them absolute.
+func getStructuredData(node *Node, variation *Variation, lang, version, image string, post bool, breadcrumb []*NavItem) []map[string]any {
+
+	result := []map[string]any{}
+
+	if variation.Filename != "" && node.Parent != nil {
+
+		article := map[string]any{
+			"@context":     "https://schema.org",
+			"@type":        getArticleType(variation, post),
+			"headline":     variation.Title,
+			"inLanguage":   lang,
+			"dateModified": getLastmod(variation.Filename).Format(time.RFC3339),
+		}
+		if baseurl != "" {
+			article["url"] = getAbsoluteLink(node, lang, version)
+			article["mainEntityOfPage"] = getAbsoluteLink(node, lang, version)
+		}
+		if variation.Description != "" {
+			article["description"] = variation.Description
+		}
+		if image != "" && baseurl != "" {
+			article["image"] = image
+		}
+		if !variation.FrontMatter.PublishDate.IsZero() {
+			article["datePublished"] = variation.FrontMatter.PublishDate.Format(time.RFC3339)
+		}
+		if len(variation.FrontMatter.Authors) > 0 {
+			authors := []map[string]any{}
+			for _, author := range variation.FrontMatter.Authors {
+				authors = append(authors, map[string]any{
+					"@type": "Person",
+					"name":  author,
+				})
+			}
+			article["author"] = authors
+		}
+		if len(variation.FrontMatter.Tags) > 0 {
+			article["keywords"] = strings.Join(variation.FrontMatter.Tags, ", ")
+		}
+
+		result = append(result, article)
+	}
+
+	if len(breadcrumb) > 0 && baseurl != "" {
+		items := []map[string]any{}
+		for i, item := range breadcrumb {
+			items = append(items, map[string]any{
+				"@type":    "ListItem",
+				"position": i + 1,
+				"name":     item.Title,
+				"item":     getAbsoluteUrl(item.Url, item.Url),
+			})
+		}
+		result = append(result, map[string]any{
+			"@context":        "https://schema.org",
+			"@type":           "BreadcrumbList",
+			"itemListElement": items,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,25 @@
+package holadoc
+
+import "testing"
+
+func TestGetArticleType(t *testing.T) {
+
+	cases := []struct {
+		frontMatter *FrontMatter
+		post        bool
+		want        string
+	}{
+		{&FrontMatter{}, false, "TechArticle"},
+		{&FrontMatter{}, true, "BlogPosting"},
+		{&FrontMatter{Layout: "posts"}, true, "BlogPosting"},
+		{&FrontMatter{Type: "blog"}, false, "BlogPosting"},
+		{&FrontMatter{Layout: "blog"}, false, "TechArticle"},
+	}
+
+	for _, c := range cases {
+		got := getArticleType(&Variation{FrontMatter: c.frontMatter}, c.post)
+		if got != c.want {
+			t.Errorf("getArticleType(%+v, %v) = %q, want %q", *c.frontMatter, c.post, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package holadoc
+
+import (
+	"cmp"
+	"html/template"
+	"slices"
+	"strings"
+)
+
+// taxonomyNames are the front matter keys that group pages by term, every
+// term gets a page listing its pages: `/tags/security/`
+var taxonomyNames = []string{"tags", "categories"}
+
+// layouts of the generated taxonomy pages, Src can override them
+const (
+	termsLayout = "terms" // index of the terms of a taxonomy
+	termLayout  = "term"  // pages of a term
+)
+
+// Taxonomies are the terms of every language and taxonomy, sorted by name
+type Taxonomies map[string]map[string][]*Term // lang: taxonomy: terms
+
+// Term is a tag or a category with the pages that declare it
+type Term struct {
+	Taxonomy string
+	Name     string
+	Url      string
+	Count    int
+
+	entries []termEntry
+}
+
+// termEntry is a page of a term, from the first version with content
+type termEntry struct {
+	node    *Node
+	version string
+}
+
+func getTerms(f *FrontMatter, taxonomy string) []string {
+	switch taxonomy {
+	case "tags":
+		return f.Tags
+	case "categories":
+		return f.Categories
+	}
+	return nil
+}
+
+// getTaxonomies collects the terms of every page. A page is taken from the
+// default version or, if it has no content there in the language, from the
+// next configured version, so it is listed once. Hidden pages are left out.
+func getTaxonomies(root *Node) Taxonomies {
+
+	taxonomies := Taxonomies{}
+
+	for _, lang := range languages {
+
+		terms := map[string]map[string]*Term{} // taxonomy: slug: term
+
+		traverseNodes(root, func(n *Node) {
+
+			if n.Parent == nil || n.Name == "{version}" {
+				return
+			}
+
+			for _, version := range versions {
+				variation := getBestVariation(n.Variations, lang, version)
+				if variation == nil || variation.Filename == "" || !hasContent(n, lang, version) {
+					continue
+				}
+				if isHidden(n, lang, version) || !isPage(n, lang, version) {
+					break
+				}
+
+				for _, taxonomy := range taxonomyNames {
+					for _, name := range getTerms(variation.FrontMatter, taxonomy) {
+						slug := slugify(name)
+						if terms[taxonomy] == nil {
+							terms[taxonomy] = map[string]*Term{}
+						}
+						term := terms[taxonomy][slug]
+						if term == nil {
+							term = &Term{
+								Taxonomy: taxonomy,
+								Name:     name,
+								Url:      getSubpageLink(root, lang, versions[0], taxonomy, slug),
+							}
+							terms[taxonomy][slug] = term
+						}
+						if last := len(term.entries) - 1; last >= 0 && term.entries[last].node == n {
+							continue // repeated in the same page
+						}
+						term.Count++
+						term.entries = append(term.entries, termEntry{node: n, version: version})
+					}
+				}
+				break
+			}
+		})
+
+		taxonomies[lang] = map[string][]*Term{}
+		for taxonomy, bySlug := range terms {
+			sorted := []*Term{}
+			for _, term := range bySlug {
+				sorted = append(sorted, term)
+			}
+			slices.SortFunc(sorted, func(a, b *Term) int {
+				return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+			})
+			taxonomies[lang][taxonomy] = sorted
+		}
+	}
+
+	return taxonomies
+}
+
+// find returns a term by name, case and accents are ignored
+func (t Taxonomies) find(lang, taxonomy, name string) *Term {
+	slug := slugify(name)
+	for _, term := range t[lang][taxonomy] {
+		if slugify(term.Name) == slug {
+			return term
+		}
+	}
+	return nil
+}
+
+// pages returns the pages of a term sorted by title, seen from current
+func (term *Term) pages(current *Node, lang string) []*Page {
+	result := []*Page{}
+	for _, entry := range term.entries {
+		if page := newPage(entry.node, current, lang, entry.version); page != nil {
+			result = append(result, page)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b *Page) int {
+		return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+	return result
+}
+
+// writeTaxonomies writes, for every language, the index of every taxonomy
+// (`/tags/`) and the page of every term (`/tags/security/`)
+func writeTaxonomies(root *Node, taxonomies Taxonomies, writer *pageWriter) {
+
+	version := versions[0] // term pages are not versioned, see getTaxonomies
+
+	for _, lang := range languages {
+		for _, taxonomy := range taxonomyNames {
+
+			terms := taxonomies[lang][taxonomy]
+			if len(terms) == 0 {
+				continue
+			}
+
+			write := func(title, layout string, data map[string]any, parts ...string) {
+
+				variation := newSectionVariation(root, lang, version)
+				variation.Title = title
+				variation.FrontMatter.Layout = layout
+
+				outputPath := getSubpageOutputPath(root, variation, lang, version, parts...)
+
+				pageData := writer.data(root, variation, lang, version)
+				pageData["alternates"] = []Alternate{} // generated pages are not translated
+				pageData["taxonomy"] = taxonomy
+				pageData["index"] = template.HTML("")
+				pageData["content"] = template.HTML("")
+				for k, v := range data {
+					pageData[k] = v
+				}
+
+				writer.write(root, variation, lang, version, outputPath, pageData)
+			}
+
+			write(translate(lang, "taxonomy."+taxonomy), termsLayout, map[string]any{
+				"terms": terms,
+			}, taxonomy)
+
+			for _, term := range terms {
+				write(term.Name, termLayout, map[string]any{
+					"term":  term,
+					"pages": term.pages(root, lang),
+				}, taxonomy, slugify(term.Name))
+			}
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package holadoc
+
+import (
+	"archive/zip"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultTheme is the theme used when the site does not provide its own
+// templates or assets. Any file can be overridden by a file with the same
+// name in Src.
+//
+//go:embed all:theme
+var defaultTheme embed.FS
+
+// Theme is a set of layouts, partials, assets and messages. Themes are
+// directories or zip files with an optional manifest `theme.json`.
+type Theme struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Version     string         `json:"version"`
+	Params      map[string]any `json:"params"` // configurable by the site
+	id          string         // key in themes, see loadTheme
+	fs          fs.FS
+}
+
+// themes in use indexed by id, the site theme is loaded on top of the
+// default theme so it only needs to provide what it changes
+var themes = map[string]*Theme{}
+
+// themePath is the absolute path of the site theme, readNodes skips it when
+// the theme lives inside Src
+var themePath string
+
+// isThemePath returns true if filename is the site theme
+func isThemePath(filename string) bool {
+	if themePath == "" {
+		return false
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		panic(err.Error())
+	}
+	return abs == themePath
+}
+
+// themePrefix marks template filenames that belong to a theme:
+// `theme:default/layouts/base.gohtml`
+const themePrefix = "theme:"
+
+// themeManifest is the file with the name and parameters of a theme
+const themeManifest = "theme.json"
+
+// directories of a theme that are not copied to the output
+var themeReserved = []string{"layouts", "partials", "i18n"}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err.Error())
+	}
+	return sub
+}
+
+func isThemeFile(filename string) bool {
+	return strings.HasPrefix(filename, themePrefix)
+}
+
+// readFile reads a file from Src or from a theme
+func readFile(filename string) ([]byte, error) {
+	if ref, ok := strings.CutPrefix(filename, themePrefix); ok {
+		name, file, _ := strings.Cut(ref, "/")
+		t, exists := themes[name]
+		if !exists {
+			return nil, errors.New("theme '" + name + "' is not loaded")
+		}
+		return fs.ReadFile(t.fs, file)
+	}
+	return os.ReadFile(filename)
+}
+
+func getDefaultTheme() *Theme {
+	return newTheme(mustSub(defaultTheme, "theme"), "default")
+}
+
+// openTheme opens a theme from a directory or a zip file
+func openTheme(filename string) *Theme {
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+
+	if info.IsDir() {
+		return newTheme(os.DirFS(filename), name)
+	}
+
+	if strings.ToLower(path.Ext(filename)) != ".zip" {
+		panic("theme '" + filename + "' must be a directory or a zip file")
+	}
+
+	z, err := zip.OpenReader(filename) // closed when the process ends
+	if err != nil {
+		panic(err.Error())
+	}
+
+	var fsys fs.FS = z
+
+	// zip files usually contain the theme directory itself
+	if _, err := fs.Stat(fsys, themeManifest); err != nil {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			panic(err.Error())
+		}
+		if len(entries) == 1 && entries[0].IsDir() {
+			fsys = mustSub(fsys, entries[0].Name())
+		}
+	}
+
+	return newTheme(fsys, name)
+}
+
+func newTheme(fsys fs.FS, name string) *Theme {
+
+	t := &Theme{
+		Name:   name,
+		Params: map[string]any{},
+		fs:     fsys,
+	}
+
+	manifest, err := fs.ReadFile(fsys, themeManifest)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		panic(err.Error())
+	}
+	if err == nil {
+		err = json.Unmarshal(manifest, t)
+		if err != nil {
+			panic(themeManifest + ": " + err.Error())
+		}
+	}
+
+	t.Name = strings.ReplaceAll(t.Name, "/", "-")
+	if t.Params == nil {
+		t.Params = map[string]any{}
+	}
+
+	return t
+}
+
+// getThemeParams returns the parameters of the themes overridden by the site
+func getThemeParams(themes []*Theme, site map[string]any) map[string]any {
+	params := map[string]any{}
+	for _, t := range themes {
+		for k, v := range t.Params {
+			params[k] = v
+		}
+	}
+	for k, v := range site {
+		params[k] = v
+	}
+	return params
+}
+
+// loadTheme registers the theme templates in the root node and copies the
+// theme assets to www. It must run before readNodes so Src files override
+// theme files.
+func loadTheme(root *Node, t *Theme, www string) {
+
+	// names are not unique, a copy of theme/ is still called "default"
+	t.id = t.Name
+	for i := 2; themes[t.id] != nil; i++ {
+		t.id = t.Name + "-" + strconv.Itoa(i)
+	}
+	themes[t.id] = t
+	prefix := themePrefix + t.id + "/"
+
+	readCatalogs(t.fs, "i18n", false)
+
+	entries, err := fs.ReadDir(t.fs, ".")
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case name == "layouts" && entry.IsDir():
+			readTemplates(t.fs, name, "", func(name, filename string) {
+				root.addLayout(name, prefix+filename)
+			})
+		case name == "partials" && entry.IsDir():
+			readTemplates(t.fs, name, "", func(name, filename string) {
+				root.addPartial(name, prefix+filename)
+			})
+		case name == defaultLayout+".gohtml":
+			root.addLayout(defaultLayout, prefix+name)
+			root.Template = prefix + name
+		case in(themeReserved, name), name == themeManifest, strings.ToLower(path.Ext(name)) == ".gohtml":
+			continue
+		default:
+			copyThemeFile(t.fs, name, path.Join(www, name))
+		}
+	}
+}
+
+func copyThemeFile(fsys fs.FS, src, dst string) {
+
+	info, err := fs.Stat(fsys, src)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if info.IsDir() {
+		err = os.MkdirAll(dst, 0777)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		entries, err := fs.ReadDir(fsys, src)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		for _, entry := range entries {
+			copyThemeFile(fsys, path.Join(src, entry.Name()), path.Join(dst, entry.Name()))
+		}
+		return
+	}
+
+	d, err := os.Create(dst)
+	if err != nil {
+		panic(err.Error())
+	}
+	s, err := fsys.Open(src)
+	if err != nil {
+		panic(err.Error())
+	}
+	_, err = io.Copy(d, s)
+	if err != nil {
+		panic(err.Error())
+	}
+	d.Close()
+	s.Close()
+}
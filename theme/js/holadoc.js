@@ -0,0 +1,475 @@
+// HolaDoc default theme
+
+(function () {
+
+    // source: https://stackoverflow.com/questions/49958471/highlight-item-in-an-index-based-on-currently-visible-content-during-scroll
+    function isElementInViewport(el) {
+
+        var rect = el.getBoundingClientRect(),
+            vWidth = window.innerWidth || document.documentElement.clientWidth,
+            vHeight = window.innerHeight || document.documentElement.clientHeight,
+            efp = function (x, y) { return document.elementFromPoint(x, y) };
+
+        // Return false if it's not in the viewport
+        if (rect.right < 0 || rect.bottom < 0
+            || rect.left > vWidth || rect.top > vHeight)
+            return false;
+
+        // Return true if any of its four corners are visible
+        return (
+            el.contains(efp(rect.left, rect.top))
+            || el.contains(efp(rect.right, rect.top))
+            || el.contains(efp(rect.right, rect.bottom))
+            || el.contains(efp(rect.left, rect.bottom))
+        );
+    }
+
+    function highlightIndex() {
+        let v = false;
+        document.querySelectorAll('.index a').forEach(a => {
+            const el = document.getElementById(decodeURIComponent(a.getAttribute('href').slice(1)));
+            if (!el) {
+                return;
+            }
+
+            if (!v && isElementInViewport(el)) {
+                a.classList.add('active');
+                v = true;
+            } else {
+                a.classList.remove('active');
+            }
+        });
+    }
+
+    document.addEventListener('scroll', highlightIndex, true);
+    window.addEventListener('load', highlightIndex, true);
+
+    // copy button for code blocks
+    function addCopyButtons() {
+        const copy = document.body.dataset.copy || 'Copy';
+        const copied = document.body.dataset.copied || 'Copied!';
+
+        document.querySelectorAll('.document pre').forEach(pre => {
+            if (pre.parentElement.closest('pre')) {
+                return; // highlighted code is nested in the original <pre>
+            }
+            const button = document.createElement('button');
+            button.className = 'copy-button';
+            button.type = 'button';
+            button.textContent = copy;
+            button.addEventListener('click', () => {
+                const clone = pre.cloneNode(true);
+                clone.querySelectorAll('a[href^="#L"]').forEach(ln => ln.parentNode.remove()); // line numbers
+                navigator.clipboard.writeText(clone.textContent).then(() => {
+                    button.textContent = copied;
+                    setTimeout(() => button.textContent = copy, 2000);
+                });
+            });
+            pre.classList.add('copyable');
+            pre.appendChild(button);
+        });
+    }
+
+    // collapsible tree, branches left out of the page are loaded from the
+    // shared fragment of the tree (data-fragment)
+    let fragment = null;
+
+    function loadFragment(tree) {
+        if (!fragment) {
+            fragment = fetch(tree.dataset.fragment)
+                .then(response => response.ok ? response.text() : '')
+                .then(html => {
+                    const div = document.createElement('div');
+                    div.innerHTML = html;
+                    return div;
+                })
+                .catch(() => document.createElement('div'));
+        }
+        return fragment;
+    }
+
+    // loadChildren inserts the children of item from the fragment
+    function loadChildren(tree, item) {
+        const next = item.nextElementSibling;
+        if (next && next.classList.contains('children')) {
+            return Promise.resolve();
+        }
+        if (!tree.dataset.fragment) {
+            return Promise.resolve();
+        }
+        const href = item.querySelector('a').getAttribute('href');
+        return loadFragment(tree).then(div => {
+            const a = Array.from(div.querySelectorAll('.item > a')).find(a => a.getAttribute('href') === href);
+            const children = a && a.parentElement.nextElementSibling;
+            if (!children || !children.classList.contains('children')) {
+                return;
+            }
+            const clone = children.cloneNode(true);
+            clone.querySelectorAll('.item.has-children').forEach(addToggle);
+            item.after(clone);
+        });
+    }
+
+    // loadAll loads every branch under scope, the search needs all of them
+    function loadAll(tree, scope) {
+        return Promise.all(Array.from(scope.querySelectorAll(':scope > .item.has-children')).map(item => {
+            return loadChildren(tree, item).then(() => {
+                const children = item.nextElementSibling;
+                if (children && children.classList.contains('children')) {
+                    return loadAll(tree, children);
+                }
+            });
+        }));
+    }
+
+    function addToggle(item) {
+        if (item.querySelector('.toggle')) {
+            return;
+        }
+        const button = document.createElement('button');
+        button.className = 'toggle';
+        button.type = 'button';
+        button.textContent = '\u203A';
+        button.addEventListener('click', () => {
+            const tree = item.closest('.tree');
+            const next = item.nextElementSibling;
+            const expanded = next && next.classList.contains('children') && getComputedStyle(next).display !== 'none';
+            if (expanded) {
+                item.classList.remove('open');
+                item.classList.add('closed');
+                return;
+            }
+            loadChildren(tree, item).then(() => {
+                item.classList.remove('closed');
+                item.classList.add('open');
+            });
+        });
+        item.appendChild(button);
+    }
+
+    function setupTree() {
+        document.querySelectorAll('.tree .item.has-children').forEach(addToggle);
+    }
+
+    // search box, filters the tree by title
+    function setupSearch() {
+        const input = document.querySelector('.search-input');
+        if (!input) {
+            return;
+        }
+
+        input.addEventListener('focus', () => {
+            const tree = input.closest('.tree');
+            if (tree.dataset.fragment) {
+                loadAll(tree, tree);
+            }
+        }, {once: true});
+
+        input.addEventListener('input', () => {
+            const query = input.value.trim().toLowerCase();
+            const tree = input.closest('.tree');
+            tree.classList.toggle('searching', query !== '');
+            tree.querySelectorAll('.item').forEach(item => {
+                const match = query === '' || item.textContent.toLowerCase().includes(query);
+                item.classList.toggle('match', query !== '' && match);
+            });
+        });
+    }
+
+    // full text search, the manifest of the index of the language and
+    // version of the page is loaded the first time the reader types, then
+    // only the shards of the words of the query
+    const searchIndexes = {};
+
+    function fetchJSON(url) {
+        return fetch(url).then(response => {
+            if (!response.ok) {
+                throw new Error(response.statusText);
+            }
+            return response.json();
+        });
+    }
+
+    function loadSearchIndex(url) {
+        if (!searchIndexes[url]) {
+            searchIndexes[url] = fetchJSON(url)
+                .then(manifest => ({
+                    url: new URL(url, location.href),
+                    analyzer: manifest.analyzer,
+                    prefix: manifest.prefix,
+                    shards: manifest.shards,
+                    docs: manifest.docs,
+                    loaded: {},
+                    terms: {},
+                    keys: [],
+                }))
+                .catch(() => ({analyzer: 'standard', prefix: 2, shards: {}, loaded: {}, terms: {}, keys: []}));
+        }
+        return searchIndexes[url];
+    }
+
+    function shardKey(index, term) {
+        const runes = Array.from(term);
+        return runes.slice(0, cjk.test(runes[0]) ? 1 : index.prefix).join('');
+    }
+
+    // loadShards fetches the shards with the terms of the query, the last
+    // token can be the prefix of many shards
+    function loadShards(index, tokens) {
+        const keys = new Set();
+        tokens.forEach((token, i) => {
+            const key = shardKey(index, token);
+            if (i === tokens.length - 1 && key === token) {
+                Object.keys(index.shards).filter(k => k.startsWith(token)).forEach(k => keys.add(k));
+            } else if (index.shards[key]) {
+                keys.add(key);
+            }
+        });
+        return Promise.all(Array.from(keys).map(key => {
+            if (!index.loaded[key]) {
+                index.loaded[key] = fetchJSON(new URL(index.shards[key], index.url))
+                    .then(terms => {
+                        Object.assign(index.terms, terms);
+                        index.keys = Object.keys(index.terms);
+                    })
+                    .catch(() => {});
+            }
+            return index.loaded[key];
+        }));
+    }
+
+    function loadDocs(index) {
+        if (!index.loadedDocs) {
+            index.loadedDocs = index.docs ? fetchJSON(new URL(index.docs, index.url)).catch(() => []) : Promise.resolve([]);
+        }
+        return index.loadedDocs;
+    }
+
+    function search(url, query) {
+        return loadSearchIndex(url).then(index => {
+            const tokens = tokenize(index, query);
+            return loadShards(index, tokens).then(() => {
+                const found = searchDocs(index, tokens);
+                if (found.length === 0) {
+                    return [];
+                }
+                return loadDocs(index).then(docs => found.map(id => docs[id]).filter(doc => doc));
+            });
+        });
+    }
+
+    // analyzers, the same rules holadoc uses to build the index (analyzers.go)
+    function standard(text) {
+        return text.toLowerCase().normalize('NFD').replace(/\p{Mn}/gu, '')
+            .split(/[^\p{L}\p{N}\p{M}]+/u).filter(t => t !== '');
+    }
+
+    function wordSet(words) {
+        return new Set(words.split(/\s+/));
+    }
+
+    const englishStopWords = wordSet('a an and are as at be but by for from has have ' +
+        'how i if in into is it its of on or that the their then there these they this ' +
+        'to was were what when where which will with you your');
+
+    const spanishStopWords = wordSet('a al como con de del desde donde el en entre es ' +
+        'esta este eso hay la las le lo los mas me muy no nos o para pero por que se sin ' +
+        'sobre son su sus tambien un una uno y ya');
+
+    function hasAnySuffix(w, ...suffixes) {
+        return suffixes.some(suffix => w.endsWith(suffix));
+    }
+
+    function stemEnglish(w) {
+        if (w.length <= 3) {
+            return w;
+        }
+        if (w.endsWith('ies') && w.length > 4) {
+            w = w.slice(0, -3) + 'y';
+        } else if (w.endsWith('sses')) {
+            w = w.slice(0, -2);
+        } else if (hasAnySuffix(w, 'xes', 'ches', 'shes', 'zes')) {
+            w = w.slice(0, -2);
+        } else if (w.endsWith('s') && !hasAnySuffix(w, 'ss', 'us', 'is')) {
+            w = w.slice(0, -1);
+        }
+        for (const suffix of ['ingly', 'edly', 'ing', 'ed']) {
+            const stem = w.slice(0, -suffix.length);
+            if (!w.endsWith(suffix) || stem.length < 3 || !/[aeiouy]/.test(stem)) {
+                continue;
+            }
+            w = stem;
+            const last = w[w.length - 1];
+            if (last === w[w.length - 2] && last < '\x7f' && !'aeiouylsz'.includes(last)) {
+                w = w.slice(0, -1);
+            }
+            break;
+        }
+        if (w.endsWith('ly') && w.length - 2 >= 3) {
+            w = w.slice(0, -2);
+        }
+        if (w.length > 4 && w.endsWith('e')) {
+            w = w.slice(0, -1);
+        }
+        return w;
+    }
+
+    function stemSpanish(w) {
+        if (w.length >= 5) {
+            if (hasAnySuffix(w, 'os', 'as', 'es')) {
+                w = w.slice(0, -2);
+            } else if (w.endsWith('s')) {
+                w = w.slice(0, -1);
+            }
+        }
+        if (w.length >= 4 && hasAnySuffix(w, 'o', 'a', 'e')) {
+            w = w.slice(0, -1);
+        }
+        return w;
+    }
+
+    const cjk = /[\p{Script=Han}\p{Script=Hiragana}\p{Script=Katakana}\p{Script=Hangul}]/u;
+
+    function bigrams(text) {
+        const result = [];
+        let other = '';
+        let run = [];
+        const flush = () => {
+            result.push(...standard(other));
+            other = '';
+            if (run.length === 1) {
+                result.push(run[0]);
+            }
+            for (let i = 0; i + 1 < run.length; i++) {
+                result.push(run[i] + run[i + 1]);
+            }
+            run = [];
+        };
+        for (const r of text) {
+            if (cjk.test(r)) {
+                if (other !== '') {
+                    flush();
+                }
+                run.push(r);
+                continue;
+            }
+            if (run.length > 0) {
+                flush();
+            }
+            other += r;
+        }
+        flush();
+        return result;
+    }
+
+    const analyzers = {
+        standard: standard,
+        en: text => standard(text).filter(w => !englishStopWords.has(w)).map(stemEnglish),
+        es: text => standard(text).filter(w => !spanishStopWords.has(w)).map(stemSpanish),
+        cjk: bigrams,
+    };
+
+    function tokenize(index, text) {
+        return (analyzers[index.analyzer] || standard)(text);
+    }
+
+    // searchDocs returns the sections that contain all the words of the
+    // query, the last word can be incomplete
+    function searchDocs(index, tokens) {
+        if (tokens.length === 0) {
+            return [];
+        }
+
+        let scores = null;
+        tokens.forEach((token, i) => {
+            const terms = i === tokens.length - 1 ? index.keys.filter(k => k.startsWith(token)) : [token];
+            const matches = new Map();
+            terms.forEach(term => {
+                const postings = index.terms[term] || [];
+                for (let j = 0; j < postings.length; j += 2) {
+                    const score = postings[j + 1] * (term === token ? 2 : 1);
+                    matches.set(postings[j], (matches.get(postings[j]) || 0) + score);
+                }
+            });
+            if (scores === null) {
+                scores = matches;
+                return;
+            }
+            const merged = new Map();
+            scores.forEach((score, doc) => {
+                if (matches.has(doc)) {
+                    merged.set(doc, score + matches.get(doc));
+                }
+            });
+            scores = merged;
+        });
+
+        return Array.from(scores)
+            .sort((a, b) => b[1] - a[1])
+            .slice(0, 10)
+            .map(([doc]) => doc);
+    }
+
+    function showResults(container, docs, noResults) {
+        container.replaceChildren();
+        if (docs.length === 0) {
+            const empty = document.createElement('div');
+            empty.className = 'search-empty';
+            empty.textContent = noResults;
+            container.appendChild(empty);
+        }
+        docs.forEach(doc => {
+            const a = document.createElement('a');
+            a.className = 'search-result';
+            a.href = doc.u;
+            const title = document.createElement('b');
+            title.textContent = doc.t;
+            a.appendChild(title);
+            if (doc.h) {
+                const heading = document.createElement('span');
+                heading.textContent = doc.h;
+                a.appendChild(heading);
+            }
+            container.appendChild(a);
+        });
+        container.hidden = false;
+    }
+
+    function setupFullTextSearch() {
+        const box = document.querySelector('.search[data-index]');
+        if (!box) {
+            return;
+        }
+        const input = box.querySelector('.search-input');
+        const results = box.querySelector('.search-results');
+
+        input.addEventListener('input', () => {
+            const query = input.value.trim();
+            if (query === '') {
+                results.hidden = true;
+                return;
+            }
+            search(box.dataset.index, query).then(docs => {
+                if (input.value.trim() !== query) {
+                    return; // the reader kept typing
+                }
+                showResults(results, docs, box.dataset.noResults);
+            });
+        });
+
+        input.addEventListener('keydown', e => {
+            if (e.key === 'Escape') {
+                input.value = '';
+                input.dispatchEvent(new Event('input'));
+            }
+        });
+    }
+
+    document.addEventListener('DOMContentLoaded', () => {
+        addCopyButtons();
+        setupTree();
+        setupSearch();
+        setupFullTextSearch();
+    });
+
+})();
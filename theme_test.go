@@ -0,0 +1,69 @@
+package holadoc
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLoadDefaultTheme(t *testing.T) {
+
+	defer func(th map[string]*Theme, c map[string]Catalog) { themes, catalogs = th, c }(themes, catalogs)
+	themes = map[string]*Theme{}
+	catalogs = map[string]Catalog{}
+
+	www := t.TempDir()
+	root := &Node{}
+	loadTheme(root, getDefaultTheme(), www)
+
+	if root.Template != themePrefix+"default/template.gohtml" {
+		t.Errorf("template = %q, want the theme template", root.Template)
+	}
+	for _, name := range []string{baseLayout, sectionLayout, blogLayout, "term", "terms"} {
+		if !isThemeFile(root.Layouts[name]) {
+			t.Errorf("layout %q = %q, want a theme file", name, root.Layouts[name])
+		}
+	}
+	for _, name := range []string{"head", "header", "footer", "sidebar", "search"} {
+		if !isThemeFile(root.Partials[name]) {
+			t.Errorf("partial %q = %q, want a theme file", name, root.Partials[name])
+		}
+	}
+	for _, name := range []string{"css/holadoc.css", "js/holadoc.js"} {
+		if _, err := os.Stat(path.Join(www, name)); err != nil {
+			t.Errorf("asset %s is not copied", name)
+		}
+	}
+	for _, name := range []string{"template.gohtml", themeManifest, "layouts", "partials", "i18n"} {
+		if _, err := os.Stat(path.Join(www, name)); err == nil {
+			t.Errorf("%s is copied as an asset", name)
+		}
+	}
+	if catalogs["en"]["footer"] == "" {
+		t.Errorf("theme catalogs are not read")
+	}
+
+	b, err := readFile(root.Layouts[baseLayout])
+	if err != nil || len(b) == 0 {
+		t.Errorf("readFile(%q) = %d bytes, %v", root.Layouts[baseLayout], len(b), err)
+	}
+}
+
+func TestGetThemeParams(t *testing.T) {
+
+	themes := []*Theme{
+		{Params: map[string]any{"primaryColor": "#000", "treeDepth": 0, "logo": ""}},
+		{Params: map[string]any{"primaryColor": "#6cd9f6"}},
+	}
+	params := getThemeParams(themes, map[string]any{"logo": "/img/logo.png"})
+
+	want := map[string]any{"primaryColor": "#6cd9f6", "treeDepth": 0, "logo": "/img/logo.png"}
+	if len(params) != len(want) {
+		t.Errorf("getThemeParams = %v, want %v", params, want)
+	}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("getThemeParams[%q] = %v, want %v", k, params[k], v)
+		}
+	}
+}
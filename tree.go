@@ -0,0 +1,116 @@
+package holadoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+)
+
+// TreeOptions limit the tree rendered in every page, big sites should not
+// carry the whole tree in every html file:
+//
+//	{{ tree "docs" "depth" 2 "collapsed" true }}
+type TreeOptions struct {
+	Depth     int  // levels included, 0 is unlimited
+	Collapsed bool // only the children of the active branch are included
+}
+
+// expand returns true if the children of item are included
+func (o TreeOptions) expand(item *NavItem) bool {
+	if o.Depth > 0 && item.Depth+1 >= o.Depth {
+		return false
+	}
+	if o.Collapsed && !item.Active {
+		return false
+	}
+	return true
+}
+
+// parseTreeOptions reads options written as key value pairs in templates
+func parseTreeOptions(args []any) (TreeOptions, error) {
+
+	options := TreeOptions{}
+
+	if len(args)%2 != 0 {
+		return options, fmt.Errorf("options must be key value pairs")
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		key, _ := args[i].(string)
+		value := args[i+1]
+		if value == nil {
+			continue // missing theme params
+		}
+		switch key {
+		case "depth":
+			depth, ok := asInt(value)
+			if !ok {
+				return options, fmt.Errorf("depth must be a number, got %T", value)
+			}
+			options.Depth = depth
+		case "collapsed":
+			options.Collapsed = asBool(value)
+		default:
+			return options, fmt.Errorf("unknown option '%v'", args[i])
+		}
+	}
+
+	return options, nil
+}
+
+// treeFragmentsDir is where shared trees are written, see writeTreeFragment
+const treeFragmentsDir = "_tree"
+
+// treeFragments already written, every page of a section shares the same one
+var treeFragments = map[string]bool{}
+
+// writeTreeFragment writes the whole tree under n, once per language and
+// version, as html (rendered with the "tree" template) and as json. Pages
+// load it to expand the branches left out by TreeOptions. It returns the url
+// of the html fragment, the json one has the same name.
+func writeTreeFragment(www string, temp *template.Template, n *Node, lang, version string) string {
+
+	name := getNodePath(n)
+	if name == "" {
+		name = "index"
+	}
+	filename := path.Join(treeFragmentsDir, lang, version, name+".html")
+	link := path.Join(basepath, filename)
+
+	if treeFragments[filename] {
+		return link
+	}
+	treeFragments[filename] = true
+
+	items := getTree(n, nil, lang, version, TreeOptions{})
+
+	b := &bytes.Buffer{}
+	err := temp.Execute(b, items)
+	if err != nil {
+		fmt.Println("WARNING:", filename+":", err.Error())
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	filename = path.Join(www, filename)
+	err = os.MkdirAll(path.Dir(filename), 0777)
+	if err != nil {
+		panic(err.Error())
+	}
+	err = os.WriteFile(filename, b.Bytes(), 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+	err = os.WriteFile(filename[:len(filename)-len(".html")]+".json", data, 0666)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return link
+}
@@ -0,0 +1,146 @@
+package holadoc
+
+import (
+	"encoding/json"
+	"html/template"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestParseTreeOptions(t *testing.T) {
+
+	cases := []struct {
+		args []any
+		want TreeOptions
+		err  bool
+	}{
+		{nil, TreeOptions{}, false},
+		{[]any{"depth", 2}, TreeOptions{Depth: 2}, false},
+		{[]any{"depth", 2.0, "collapsed", true}, TreeOptions{Depth: 2, Collapsed: true}, false}, // json numbers
+		{[]any{"collapsed", "true"}, TreeOptions{Collapsed: true}, false},
+		{[]any{"depth", nil, "collapsed", nil}, TreeOptions{}, false}, // missing theme params
+		{[]any{"depth"}, TreeOptions{}, true},
+		{[]any{"depth", "two"}, TreeOptions{}, true},
+		{[]any{"width", 2}, TreeOptions{}, true},
+	}
+
+	for _, c := range cases {
+		got, err := parseTreeOptions(c.args)
+		if (err != nil) != c.err {
+			t.Errorf("parseTreeOptions(%v) error = %v, want error %v", c.args, err, c.err)
+		}
+		if !c.err && got != c.want {
+			t.Errorf("parseTreeOptions(%v) = %+v, want %+v", c.args, got, c.want)
+		}
+	}
+}
+
+// newTestTree returns docs/{a/{a1/{a11}}, b/{b1}} with a page for every node
+func newTestTree() (root *Node, nodes map[string]*Node) {
+	root = &Node{}
+	nodes = map[string]*Node{}
+	var add func(parent *Node, p string, children ...string)
+	add = func(parent *Node, p string, children ...string) {
+		name := path.Base(p)
+		n := &Node{Name: name, Parent: parent, Variations: []*Variation{
+			{Title: name, Language: "en", FrontMatter: &FrontMatter{}},
+		}}
+		parent.Children = append(parent.Children, n)
+		nodes[p] = n
+		for _, child := range children {
+			add(n, path.Join(p, child))
+		}
+	}
+	add(root, "docs")
+	add(nodes["docs"], "docs/a", "a1")
+	add(nodes["docs/a/a1"], "docs/a/a1/a11")
+	add(nodes["docs"], "docs/b", "b1")
+	return root, nodes
+}
+
+// treeString writes the tree as name(children), items with children left out
+// end with +
+func treeString(items []*NavItem) string {
+	parts := []string{}
+	for _, item := range items {
+		s := item.Title
+		if len(item.Children) > 0 {
+			s += "(" + treeString(item.Children) + ")"
+		} else if item.HasChildren {
+			s += "+"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestGetTreeOptions(t *testing.T) {
+
+	defer func(l, v []string) { languages, versions = l, v }(languages, versions)
+	languages = []string{"en"}
+	versions = []string{""}
+
+	_, nodes := newTestTree()
+
+	cases := []struct {
+		options TreeOptions
+		want    string
+	}{
+		{TreeOptions{}, "a(a1(a11)) b(b1)"},
+		{TreeOptions{Depth: 1}, "a+ b+"},
+		{TreeOptions{Depth: 2}, "a(a1+) b(b1)"},
+		{TreeOptions{Collapsed: true}, "a(a1(a11)) b+"}, // the current page is a1
+		{TreeOptions{Depth: 2, Collapsed: true}, "a(a1+) b+"},
+	}
+
+	for _, c := range cases {
+		got := treeString(getTree(nodes["docs"], nodes["docs/a/a1"], "en", "", c.options))
+		if got != c.want {
+			t.Errorf("getTree(%+v) = %q, want %q", c.options, got, c.want)
+		}
+	}
+}
+
+func TestWriteTreeFragment(t *testing.T) {
+
+	defer func(l, v []string, f map[string]bool) { languages, versions, treeFragments = l, v, f }(languages, versions, treeFragments)
+	languages = []string{"en"}
+	versions = []string{""}
+	treeFragments = map[string]bool{}
+
+	_, nodes := newTestTree()
+	www := t.TempDir()
+	temp := template.Must(template.New("tree").Parse(`{{ range . }}{{ .Title }};{{ end }}`))
+
+	link := writeTreeFragment(www, temp, nodes["docs"], "en", "")
+	if link != "/_tree/en/docs.html" {
+		t.Errorf("link = %q", link)
+	}
+
+	b, err := os.ReadFile(path.Join(www, "_tree/en/docs.html"))
+	if err != nil || string(b) != "a;b;" {
+		t.Errorf("html fragment = %q, %v", b, err)
+	}
+
+	b, err = os.ReadFile(path.Join(www, "_tree/en/docs.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []*NavItem{}
+	err = json.Unmarshal(b, &items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := treeString(items); got != "a(a1(a11)) b(b1)" {
+		t.Errorf("json fragment = %q, want the whole tree", got)
+	}
+
+	// written once, every page of the section shares it
+	os.Remove(path.Join(www, "_tree/en/docs.html"))
+	writeTreeFragment(www, temp, nodes["docs"], "en", "")
+	if _, err := os.Stat(path.Join(www, "_tree/en/docs.html")); err == nil {
+		t.Errorf("the fragment is written again")
+	}
+}